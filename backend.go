@@ -0,0 +1,26 @@
+package FlowWatch
+
+import "context"
+
+// Backend is the interface of the logging library behind a LogHelper, which enables simpler switching between
+// logging libraries. The default backend is the LogrusBackend.
+type Backend interface {
+	// Log writes the entry if the level is enabled. Entries at the panic level panic afterward, while the program
+	// is terminated by the LogHelper after entries at the fatal level (refer to WithExitFunc).
+	Log(level Level, ctx context.Context, fields Fields, msg string)
+
+	// IsLevelEnabled reports whether entries at the level are written.
+	IsLevelEnabled(level Level) bool
+
+	// GetLevel returns the current log level.
+	GetLevel() Level
+
+	// SetLevel updates the log level.
+	SetLevel(level Level)
+
+	// Child returns a backend sharing the output and configuration, but with its own log level (used for named loggers).
+	Child() Backend
+
+	// Flush writes any buffered entries to the output.
+	Flush() error
+}
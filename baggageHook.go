@@ -0,0 +1,52 @@
+package FlowWatch
+
+import (
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/baggage"
+	"strings"
+)
+
+// LogrusBaggageHook is a hook for logrus that copies selected baggage members of the context into the log entry, so
+// that cross-service context attached upstream (e.g. the tenant or request ID) appears in downstream logs. Existing
+// fields are not overwritten.
+type LogrusBaggageHook struct {
+	keys []string
+}
+
+// NewBaggageHook creates a hook copying the baggage members with the keys into fields of the same name.
+func NewBaggageHook(keys ...string) *LogrusBaggageHook {
+	hook := &LogrusBaggageHook{}
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			hook.keys = append(hook.keys, key)
+		}
+	}
+	return hook
+}
+
+// Levels returns all log levels for which the LogrusBaggageHook should be activated (all levels).
+func (hook *LogrusBaggageHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire is called when the LogrusBaggageHook is activated (when a log entry is made).
+func (hook *LogrusBaggageHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+
+	bag := baggage.FromContext(entry.Context)
+	if bag.Len() == 0 {
+		return nil
+	}
+
+	for _, key := range hook.keys {
+		if _, exists := entry.Data[key]; exists {
+			continue
+		}
+		if member := bag.Member(key); member.Key() != "" {
+			entry.Data[key] = member.Value()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of the batching of the remote sinks.
+const (
+	DefaultBatchInterval       = 5 * time.Second
+	DefaultBatchRetries        = 5
+	DefaultBatchBackoff        = 200 * time.Millisecond
+	DefaultBatchBufferedEvents = 10000    // Events buffered while the remote service is unreachable or throttling
+	DefaultBatchBufferedBytes  = 16 << 20 // Size of the lines buffered while the remote service is unreachable
+)
+
+// sinkEventsDropped counts the events of all remote sinks dropped because the buffer was full or the sink was closed
+// before they could be sent.
+var sinkEventsDropped atomic.Int64
+
+// SinkEventsDropped returns the number of entries the remote sinks (CloudWatch Logs, Loki, Sentry, GELF and syslog)
+// dropped since the start of the program, because their buffer was full or they were closed before the entries could
+// be sent.
+func SinkEventsDropped() int64 {
+	return sinkEventsDropped.Load()
+}
+
+// BatchClosedError is returned if entries are flushed after the sink has been closed.
+var BatchClosedError = errors.New("Sink closed")
+
+// batchEvent is a formatted entry waiting to be sent by a remote sink.
+type batchEvent struct {
+	time  time.Time
+	level Level
+	line  string
+}
+
+// batchConfig configures a batcher.
+type batchConfig struct {
+	maxEvents int           // Number of events at which the batch is sent
+	maxBytes  int           // Size of the lines at which the batch is sent
+	interval  time.Duration // Interval after which the batch is sent regardless of its size
+	retries   int           // Number of retries of a failed send
+	backoff   time.Duration // Delay before the first retry, doubled for every further retry
+
+	maxBufferedEvents int // Number of events waiting to be sent, beyond which new events are dropped
+	maxBufferedBytes  int // Size of the lines waiting to be sent, beyond which new events are dropped
+}
+
+// batcher collects the events of a remote sink and sends them in batches from a background goroutine. Failed sends
+// are retried with exponential backoff and requeued afterward, so that throttling by the remote service does not lose
+// entries. The buffer is bounded, so that an unreachable service cannot exhaust the memory: events exceeding it are
+// dropped and counted (refer to SinkEventsDropped).
+type batcher struct {
+	config batchConfig
+	send   func(ctx context.Context, events []batchEvent) error
+
+	mu     sync.Mutex
+	events []batchEvent
+	size   int
+	closed bool
+
+	sendMu  sync.Mutex    // Serializes the sends to keep the events in order
+	trigger chan struct{} // Signals a full batch to the background goroutine
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// newBatcher creates a batcher sending the batches with the function and starts its background goroutine.
+func newBatcher(config batchConfig, send func(ctx context.Context, events []batchEvent) error) *batcher {
+	if config.interval <= 0 {
+		config.interval = DefaultBatchInterval
+	}
+	if config.retries < 0 {
+		config.retries = 0
+	}
+	if config.backoff <= 0 {
+		config.backoff = DefaultBatchBackoff
+	}
+	if config.maxBufferedEvents <= 0 {
+		config.maxBufferedEvents = DefaultBatchBufferedEvents
+	}
+	if config.maxBufferedBytes <= 0 {
+		config.maxBufferedBytes = DefaultBatchBufferedBytes
+	}
+
+	b := &batcher{
+		config:  config,
+		send:    send,
+		trigger: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go b.run()
+
+	return b
+}
+
+// add adds the event to the batch and triggers the send if the batch is full. The event is dropped if the buffer is
+// full.
+func (b *batcher) add(event batchEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		sinkEventsDropped.Add(1)
+		return
+	}
+	if len(b.events) >= b.config.maxBufferedEvents || b.size+len(event.line) > b.config.maxBufferedBytes {
+		sinkEventsDropped.Add(1)
+		return
+	}
+	b.events = append(b.events, event)
+	b.size += len(event.line)
+
+	if b.exceeds(len(b.events)+1, b.size+1) { // The batch is full if one more event would exceed the limits
+		select {
+		case b.trigger <- struct{}{}:
+		default: // A send has already been triggered
+		}
+	}
+}
+
+// run sends the batch periodically or when it is full until the batcher is closed.
+func (b *batcher) run() {
+	defer close(b.stopped)
+
+	ticker := time.NewTicker(b.config.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.trigger:
+		case <-b.stop:
+			return
+		}
+		_ = b.flush(context.Background()) // Failed batches are requeued and sent with the next flush
+	}
+}
+
+// flush sends all collected events in batches within the limits. If a batch fails after the retries, it is requeued
+// with the remaining events in front of the events added in the meantime.
+func (b *batcher) flush(ctx context.Context) error {
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+
+	b.mu.Lock()
+	events := b.events
+	b.events, b.size = nil, 0
+	b.mu.Unlock()
+
+	for len(events) > 0 {
+		n := b.batchSize(events)
+		if err := b.sendWithRetry(ctx, events[:n]); err != nil {
+			b.requeue(events)
+			return err
+		}
+		events = events[n:]
+	}
+
+	return nil
+}
+
+// requeue puts the unsent events in front of the events added in the meantime. The newest events exceeding the
+// buffer are dropped.
+func (b *batcher) requeue(unsent []batchEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make([]batchEvent, 0, len(unsent)+len(b.events))
+	size := 0
+	for _, pending := range [][]batchEvent{unsent, b.events} {
+		for _, event := range pending {
+			if len(events) >= b.config.maxBufferedEvents || size+len(event.line) > b.config.maxBufferedBytes {
+				sinkEventsDropped.Add(1)
+				continue
+			}
+			events = append(events, event)
+			size += len(event.line)
+		}
+	}
+	b.events, b.size = events, size
+}
+
+// batchSize returns the number of events of the first batch within the limits (at least one).
+func (b *batcher) batchSize(events []batchEvent) int {
+	size := 0
+	for i, event := range events {
+		size += len(event.line)
+		if i > 0 && b.exceeds(i+1, size) {
+			return i
+		}
+	}
+	return len(events)
+}
+
+// exceeds reports whether a batch with the number of events and size exceeds the limits.
+func (b *batcher) exceeds(events, size int) bool {
+	return (b.config.maxEvents > 0 && events > b.config.maxEvents) || (b.config.maxBytes > 0 && size > b.config.maxBytes)
+}
+
+// sendWithRetry sends the batch and retries it with exponential backoff if it fails.
+func (b *batcher) sendWithRetry(ctx context.Context, events []batchEvent) error {
+	backoff := b.config.backoff
+	for attempt := 0; ; attempt++ {
+		err := b.send(ctx, events)
+		if err == nil || attempt >= b.config.retries {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Wrap(ctx.Err(), err.Error())
+		}
+		backoff *= 2
+	}
+}
+
+// Flush sends all collected events.
+func (b *batcher) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return BatchClosedError
+	}
+
+	return b.flush(ctx)
+}
+
+// Close stops the background goroutine and sends the remaining events.
+func (b *batcher) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.stop)
+	<-b.stopped
+
+	err := b.flush(ctx)
+
+	// The events requeued by a failed flush cannot be sent anymore
+	b.mu.Lock()
+	sinkEventsDropped.Add(int64(len(b.events)))
+	b.events, b.size = nil, 0
+	b.mu.Unlock()
+
+	return err
+}
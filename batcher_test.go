@@ -0,0 +1,123 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSink collects the batches sent by a batcher and fails while failing is set.
+type recordingSink struct {
+	mu      sync.Mutex
+	batches [][]string
+	failing bool
+}
+
+func (s *recordingSink) send(_ context.Context, events []batchEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failing {
+		return errors.New("Unavailable")
+	}
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		lines = append(lines, event.line)
+	}
+	s.batches = append(s.batches, lines)
+	return nil
+}
+
+func (s *recordingSink) setFailing(failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = failing
+}
+
+func (s *recordingSink) lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lines []string
+	for _, batch := range s.batches {
+		lines = append(lines, batch...)
+	}
+	return lines
+}
+
+// addLines adds the events "0" to "n-1" to the batcher.
+func addLines(b *batcher, n int) {
+	for i := 0; i < n; i++ {
+		b.add(batchEvent{time: time.Now(), level: Info, line: strconv.Itoa(i)})
+	}
+}
+
+func TestBatcherSplitsBatches(t *testing.T) {
+	sink := &recordingSink{}
+	b := newBatcher(batchConfig{maxEvents: 3, interval: time.Hour, retries: -1}, sink.send)
+	defer func() { _ = b.Close(context.Background()) }()
+
+	addLines(b, 7)
+	if err := b.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := sink.lines(); len(lines) != 7 {
+		t.Errorf("Sent %d events, want 7", len(lines))
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	for _, batch := range sink.batches {
+		if len(batch) > 3 {
+			t.Errorf("Batch of %d events exceeds the limit of 3", len(batch))
+		}
+	}
+}
+
+func TestBatcherRequeuesFailedBatches(t *testing.T) {
+	sink := &recordingSink{failing: true}
+	b := newBatcher(batchConfig{interval: time.Hour, retries: -1}, sink.send)
+	defer func() { _ = b.Close(context.Background()) }()
+
+	addLines(b, 2)
+	if err := b.Flush(context.Background()); err == nil {
+		t.Fatal("Flush succeeded with a failing sink")
+	}
+	b.add(batchEvent{line: "2"})
+
+	sink.setFailing(false)
+	if err := b.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := sink.lines()
+	if len(lines) != 3 || lines[0] != "0" || lines[1] != "1" || lines[2] != "2" {
+		t.Errorf("Sent %v, want the requeued events in front of the new one", lines)
+	}
+}
+
+func TestBatcherBoundsBuffer(t *testing.T) {
+	sink := &recordingSink{failing: true}
+	b := newBatcher(batchConfig{interval: time.Hour, retries: -1, maxBufferedEvents: 5}, sink.send)
+
+	before := SinkEventsDropped()
+	addLines(b, 8)
+	if got := SinkEventsDropped() - before; got != 3 {
+		t.Errorf("Dropped %d events beyond the buffer, want 3", got)
+	}
+
+	// The events which cannot be sent anymore are counted when the sink is closed
+	before = SinkEventsDropped()
+	_ = b.Close(context.Background())
+	if got := SinkEventsDropped() - before; got != 5 {
+		t.Errorf("Dropped %d events on close, want 5", got)
+	}
+
+	b.add(batchEvent{line: "late"})
+	if err := b.Flush(context.Background()); !errors.Is(err, BatchClosedError) {
+		t.Errorf("Flush after Close returned %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package FlowWatch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/sirupsen/logrus"
+	"sync/atomic"
+)
+
+// DefaultBlobThreshold is the default size in bytes above which []byte fields are replaced by a digest.
+const DefaultBlobThreshold = 1024
+
+// BlobStore is an external storage for large binary fields. Store persists the data and returns a reference
+// (e.g. an object URL), which is logged instead of the data.
+type BlobStore interface {
+	Store(ctx context.Context, data []byte) (string, error)
+}
+
+var (
+	blobThreshold atomic.Int64
+	blobStore     atomic.Pointer[BlobStore]
+)
+
+func init() {
+	blobThreshold.Store(DefaultBlobThreshold)
+}
+
+// SetBlobThreshold updates the size in bytes above which []byte fields are replaced by a digest.
+// A threshold of zero or less disables the replacement.
+func SetBlobThreshold(threshold int) {
+	blobThreshold.Store(int64(threshold))
+}
+
+// SetBlobStore configures the store to which large []byte fields are uploaded. The returned reference is logged
+// alongside the digest. Pass nil to only log the digest.
+func SetBlobStore(store BlobStore) {
+	if store == nil {
+		blobStore.Store(nil)
+		return
+	}
+	blobStore.Store(&store)
+}
+
+// LogrusBlobHook is a hook for logrus that replaces large []byte fields with their SHA-256 digest and size
+// (and optionally a reference to the blob store), keeping payload forensics possible without bloating the logs.
+type LogrusBlobHook struct{}
+
+// Levels returns all log levels for which the LogrusBlobHook should be activated (all levels).
+func (hook LogrusBlobHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire is called when the LogrusBlobHook is activated (when a log entry is made).
+func (hook LogrusBlobHook) Fire(entry *logrus.Entry) error {
+	replaceBlobs(entry.Context, hookFields(entry))
+	return nil
+}
+
+// replaceBlobs replaces the []byte fields above the threshold with their digest and size (and the reference to the
+// blob store if configured).
+func replaceBlobs(ctx context.Context, data *entryFields) {
+	threshold := int(blobThreshold.Load())
+	if threshold <= 0 {
+		return
+	}
+
+	var blobs []string
+	for key, value := range data.data {
+		if blob, ok := value.([]byte); ok && len(blob) > threshold {
+			blobs = append(blobs, key)
+		}
+	}
+
+	for _, key := range blobs {
+		blob := data.data[key].([]byte)
+		digest := sha256.Sum256(blob)
+		replacement := map[string]interface{}{
+			"sha256": hex.EncodeToString(digest[:]),
+			"size":   len(blob),
+		}
+
+		// Upload the data if a blob store is configured
+		if store := blobStore.Load(); store != nil {
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			ref, err := (*store).Store(ctx, blob)
+			if err != nil {
+				replacement["store_error"] = err.Error() // Other hooks must still be executed, so the error is not returned
+			} else {
+				replacement["ref"] = ref
+			}
+		}
+
+		data.set(key, replacement)
+	}
+}
@@ -0,0 +1,114 @@
+package FlowWatch
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCallerDepth is the number of frames searched for the caller of the log function.
+const maxCallerDepth = 32
+
+// callerCache caches the resolved frames by program counter (uintptr -> []callerInfo), since resolving them is
+// expensive and the same log statements are hit repeatedly.
+var callerCache sync.Map
+
+// callerInfo is a resolved frame with the package of its function.
+type callerInfo struct {
+	frame   runtime.Frame
+	pkg     string
+	logging bool // Whether the frame belongs to one of the loggingPackages
+}
+
+// callerSkip is the number of additional frames skipped to find the caller (refer to SetCallerSkip).
+var callerSkip atomic.Int32
+
+// flowWatchPackage is the import path of this package.
+var flowWatchPackage = reflect.TypeOf((*LogHelper)(nil)).Elem().PkgPath()
+
+// loggingPackages are the packages whose frames are skipped to find the caller of the log function.
+var loggingPackages = map[string]bool{
+	flowWatchPackage:                    true,
+	flowWatchPackage + "/otelHelper":    true,
+	flowWatchPackage + "/flowwatchtest": true,
+	"github.com/sirupsen/logrus":        true,
+	"go.uber.org/zap":                   true,
+	"go.uber.org/zap/zapcore":           true,
+	"fmt":                               true,
+	"log":                               true,
+	"log/slog":                          true,
+	"runtime":                           true,
+}
+
+// SetCallerSkip sets the number of additional frames to skip when determining the file and line of an entry, e.g. 1
+// if all entries are logged through a helper function of the application. The frames of FlowWatch and the logging
+// libraries are always skipped.
+func SetCallerSkip(skip int) {
+	callerSkip.Store(int32(max(skip, 0)))
+}
+
+// Caller returns the file and line of the function which logged the current entry (the first frame outside FlowWatch
+// and the logging libraries, refer to SetCallerSkip), e.g. for custom backends.
+func Caller() (file string, line int, ok bool) {
+	_, frame, ok := callerFrame()
+	return frame.File, frame.Line, ok
+}
+
+// callerFrame returns the program counter and frame of the first function outside the logging packages. If the entry
+// has been logged by FlowWatch itself (e.g. from a timer), the outermost FlowWatch frame is returned instead.
+func callerFrame() (uintptr, runtime.Frame, bool) {
+	var pcs [maxCallerDepth]uintptr
+	n := runtime.Callers(2, pcs[:]) // Skip runtime.Callers and callerFrame
+
+	skip := callerSkip.Load()
+	var fallbackPC uintptr
+	var fallback runtime.Frame
+	for _, pc := range pcs[:n] {
+		for _, info := range resolveCaller(pc) {
+			switch {
+			case !info.logging && skip == 0:
+				return pc, info.frame, true
+			case !info.logging:
+				skip--
+			case info.pkg == flowWatchPackage:
+				fallbackPC, fallback = pc, info.frame
+			}
+		}
+	}
+
+	return fallbackPC, fallback, fallbackPC != 0
+}
+
+// resolveCaller returns the frames of the program counter from the cache or resolves them. Each program counter is
+// resolved separately to know which one the caller frame belongs to (a program counter resolves to multiple frames if
+// functions have been inlined).
+func resolveCaller(pc uintptr) []callerInfo {
+	if cached, ok := callerCache.Load(pc); ok {
+		return cached.([]callerInfo)
+	}
+
+	var infos []callerInfo
+	frames := runtime.CallersFrames([]uintptr{pc})
+	for more := true; more; {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+
+		pkg := functionPackage(frame.Function)
+		infos = append(infos, callerInfo{frame: frame, pkg: pkg, logging: loggingPackages[pkg]})
+	}
+	callerCache.Store(pc, infos)
+
+	return infos
+}
+
+// functionPackage returns the import path of the package of the fully qualified function name.
+func functionPackage(name string) string {
+	// e.g. github.com/org/service/internal/payments.(*Client).Charge
+	lastSlash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[lastSlash+1:], "."); dot >= 0 {
+		return name[:lastSlash+1+dot]
+	}
+	return name
+}
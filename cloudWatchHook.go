@@ -0,0 +1,175 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"github.com/sirupsen/logrus"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits of a PutLogEvents request (each event counts 26 bytes in addition to its message).
+const (
+	cloudWatchMaxEvents     = 10000
+	cloudWatchMaxBatchBytes = 1048576 - cloudWatchMaxEvents*26
+)
+
+// CloudWatchEvent is a log event of a PutLogEvents request.
+type CloudWatchEvent struct {
+	Timestamp time.Time
+	Message   string
+}
+
+// CloudWatchPutInput is the input of a PutLogEvents request with the events in chronological order.
+type CloudWatchPutInput struct {
+	LogGroup      string
+	LogStream     string
+	SequenceToken string // Empty for the first request of a stream
+	Events        []CloudWatchEvent
+}
+
+// InvalidSequenceTokenError is returned by a CloudWatchLogsClient if CloudWatch rejected the sequence token. The
+// request is retried with the expected token.
+type InvalidSequenceTokenError struct {
+	ExpectedSequenceToken string
+}
+
+// Error returns the message of the error.
+func (e *InvalidSequenceTokenError) Error() string {
+	return "Invalid sequence token, expected " + e.ExpectedSequenceToken
+}
+
+// CloudWatchLogsClient sends log events to CloudWatch Logs. It is implemented by a small adapter around the
+// PutLogEvents call of the AWS SDK, so that FlowWatch does not depend on the SDK. PutLogEvents returns the next
+// sequence token.
+type CloudWatchLogsClient interface {
+	PutLogEvents(ctx context.Context, input CloudWatchPutInput) (string, error)
+}
+
+// CloudWatchConfig configures the LogrusCloudWatchHook. The log group and stream support the placeholders {service}
+// (OTEL_SERVICE_NAME), {hostname}, {pod} (POD_NAME, default: the hostname), {pid} and {date} (e.g. 2025/01/01).
+type CloudWatchConfig struct {
+	Client        CloudWatchLogsClient
+	LogGroup      string        // e.g. /services/{service}
+	LogStream     string        // e.g. {pod}/{date} (default: {hostname}/{pid})
+	FlushInterval time.Duration // Interval after which the batch is sent (default: DefaultBatchInterval)
+	MaxRetries    int           // Retries of throttled or failed requests (default: DefaultBatchRetries, < 0 disables them)
+	MaxBuffered   int           // Entries buffered while the service is unreachable (default: DefaultBatchBufferedEvents)
+}
+
+// LogrusCloudWatchHook is a hook for logrus that batches the formatted entries and pushes them to CloudWatch Logs,
+// e.g. for Lambdas and ECS tasks which cannot run a collector sidecar. Failed requests (e.g. throttling) are retried
+// with exponential backoff.
+type LogrusCloudWatchHook struct {
+	client    CloudWatchLogsClient
+	logGroup  string
+	logStream string
+	batcher   *batcher
+
+	mu            sync.Mutex
+	sequenceToken string
+}
+
+// NewCloudWatchHook creates a hook pushing the entries to the log group and stream of the config.
+func NewCloudWatchHook(config CloudWatchConfig) *LogrusCloudWatchHook {
+	if config.LogStream == "" {
+		config.LogStream = "{hostname}/{pid}"
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = DefaultBatchRetries
+	}
+
+	hook := &LogrusCloudWatchHook{
+		client:    config.Client,
+		logGroup:  expandSinkTemplate(config.LogGroup),
+		logStream: expandSinkTemplate(config.LogStream),
+	}
+	hook.batcher = newBatcher(batchConfig{
+		maxEvents: cloudWatchMaxEvents,
+		maxBytes:  cloudWatchMaxBatchBytes,
+		interval:  config.FlushInterval,
+		retries:   config.MaxRetries,
+
+		maxBufferedEvents: config.MaxBuffered,
+	}, hook.send)
+
+	return hook
+}
+
+// Levels returns all log levels for which the LogrusCloudWatchHook should be activated (all levels).
+func (hook *LogrusCloudWatchHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire is called when the LogrusCloudWatchHook is activated (when a log entry is made).
+func (hook *LogrusCloudWatchHook) Fire(entry *logrus.Entry) error {
+	line, err := entry.Logger.Formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	hook.batcher.add(batchEvent{
+		time:  entry.Time,
+		level: levelFromLogrus(entry.Level),
+		line:  strings.TrimSuffix(string(line), "\n"),
+	})
+	return nil
+}
+
+// Flush pushes the collected entries.
+func (hook *LogrusCloudWatchHook) Flush(ctx context.Context) error {
+	return hook.batcher.Flush(ctx)
+}
+
+// Close pushes the remaining entries and stops the background goroutine.
+func (hook *LogrusCloudWatchHook) Close(ctx context.Context) error {
+	return hook.batcher.Close(ctx)
+}
+
+// send pushes the batch with the current sequence token and updates the token from the response.
+func (hook *LogrusCloudWatchHook) send(ctx context.Context, events []batchEvent) error {
+	input := CloudWatchPutInput{LogGroup: hook.logGroup, LogStream: hook.logStream}
+	for _, event := range events {
+		input.Events = append(input.Events, CloudWatchEvent{Timestamp: event.time, Message: event.line})
+	}
+	sort.SliceStable(input.Events, func(i, j int) bool { // CloudWatch requires chronological events
+		return input.Events[i].Timestamp.Before(input.Events[j].Timestamp)
+	})
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	input.SequenceToken = hook.sequenceToken
+	token, err := hook.client.PutLogEvents(ctx, input)
+	if tokenErr, ok := err.(*InvalidSequenceTokenError); ok {
+		hook.sequenceToken = tokenErr.ExpectedSequenceToken // Used by the retry
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	hook.sequenceToken = token
+	return nil
+}
+
+// expandSinkTemplate replaces the placeholders of a log group, stream or label template.
+func expandSinkTemplate(template string) string {
+	hostname, _ := os.Hostname()
+	pod := otelHelper.Getenv("POD_NAME")
+	if pod == "" {
+		pod = hostname
+	}
+
+	return strings.NewReplacer(
+		"{service}", otelHelper.Getenv("OTEL_SERVICE_NAME"),
+		"{hostname}", hostname,
+		"{pod}", pod,
+		"{pid}", strconv.Itoa(os.Getpid()),
+		"{date}", time.Now().UTC().Format("2006/01/02"),
+	).Replace(template)
+}
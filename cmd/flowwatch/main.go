@@ -0,0 +1,53 @@
+// Command flowwatch provides utilities for the local analysis of logs written by FlowWatch.
+//
+// Usage:
+//
+//	flowwatch unfold [file]
+//
+// unfold reconstructs readable entries (e.g. stack traces) from folded log lines read from the file or stdin.
+package main
+
+import (
+	"fmt"
+	"github.com/LucaSchmitz2003/FlowWatch"
+	"io"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "unfold":
+		if err := unfold(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "flowwatch: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		usage()
+	}
+}
+
+// unfold copies the unfolded logs from the given file (or stdin) to stdout.
+func unfold(args []string) error {
+	input := io.Reader(os.Stdin)
+	if len(args) > 0 && args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	_, err := io.Copy(os.Stdout, FlowWatch.NewUnfoldReader(input))
+	return err
+}
+
+// usage prints the usage information and exits.
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: flowwatch unfold [file]")
+	os.Exit(2)
+}
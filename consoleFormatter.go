@@ -0,0 +1,144 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// consoleMessageWidth is the width to which the messages are padded, so that the fields are aligned.
+const consoleMessageWidth = 44
+
+// ANSI color codes of the levels.
+const (
+	colorGray   = 90
+	colorRed    = 31
+	colorGreen  = 32
+	colorYellow = 33
+	colorCyan   = 36
+)
+
+// ConsoleFormatter is a logrus formatter writing human-readable lines for local development, e.g.
+// "15:04:05.000 WARN  Cache miss          key=users file=cache/cache.go:42". It is selected automatically if the
+// output is a terminal and ENV is set to dev.
+type ConsoleFormatter struct {
+	DisableColors   bool
+	TimestampFormat string // Default: 15:04:05.000
+}
+
+// Format formats the entry as a single line with the level-colored fields sorted by key.
+func (f *ConsoleFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = "15:04:05.000"
+	}
+	color := levelColor(entry.Level)
+
+	var buf bytes.Buffer
+	buf.WriteString(f.colorize(colorGray, entry.Time.Format(timestampFormat)))
+	buf.WriteByte(' ')
+	buf.WriteString(f.colorize(color, fmt.Sprintf("%-5s", consoleLevel(entry.Level))))
+	buf.WriteByte(' ')
+
+	message := strings.TrimSuffix(entry.Message, "\n")
+	if len(entry.Data) > 0 && len(message) < consoleMessageWidth {
+		message += strings.Repeat(" ", consoleMessageWidth-len(message))
+	}
+	buf.WriteString(message)
+
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		if key != "file" && key != "line" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, " %s=%v", f.colorize(color, key), entry.Data[key])
+	}
+
+	// Add the caller with a short path (the last directory and the file name)
+	if file, ok := entry.Data["file"].(string); ok {
+		short := filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file))
+		fmt.Fprintf(&buf, " %s=%s:%v", f.colorize(color, "file"), short, entry.Data["line"])
+	}
+
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// colorize wraps the text in the ANSI escape sequence of the color unless the colors are disabled.
+func (f *ConsoleFormatter) colorize(color int, text string) string {
+	if f.DisableColors {
+		return text
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", color, text)
+}
+
+// levelColor returns the ANSI color code of the level.
+func levelColor(level logrus.Level) int {
+	switch level {
+	case logrus.TraceLevel, logrus.DebugLevel:
+		return colorCyan
+	case logrus.InfoLevel:
+		return colorGreen
+	case logrus.WarnLevel:
+		return colorYellow
+	default:
+		return colorRed
+	}
+}
+
+// consoleLevel returns the short upper case name of the level.
+func consoleLevel(level logrus.Level) string {
+	if level == logrus.WarnLevel {
+		return "WARN"
+	}
+	return strings.ToUpper(level.String())
+}
+
+// defaultFormatter returns the formatter used if none has been set with WithFormatter: the format from
+// FLOWWATCH_FORMAT (json, logfmt, ecs, gcp, console or a registered format) if set, the ConsoleFormatter if the
+// output is a terminal and ENV is set to dev, otherwise JSON with RFC 3339 timestamps and structured errors.
+func defaultFormatter(output io.Writer) logrus.Formatter {
+	format := otelHelper.Getenv("FLOWWATCH_FORMAT")
+	if formatter, ok := registeredFormatter(format); ok {
+		return formatter
+	}
+
+	switch strings.ToLower(format) {
+	case "logfmt":
+		return &LogfmtFormatter{}
+	case "ecs":
+		return &ECSFormatter{}
+	case "gcp":
+		return &GCPFormatter{ProjectID: otelHelper.Getenv("GOOGLE_CLOUD_PROJECT")}
+	case "console":
+		return &ConsoleFormatter{DisableColors: !isTerminal(output)}
+	case "json":
+	default:
+		if otelHelper.Getenv("ENV") == "dev" && isTerminal(output) {
+			return &ConsoleFormatter{}
+		}
+	}
+	return &JSONFormatter{JSONFormatter: logrus.JSONFormatter{
+		TimestampFormat: time.RFC3339,
+	}}
+}
+
+// isTerminal reports whether the output is a terminal (character device).
+func isTerminal(output io.Writer) bool {
+	file, ok := output.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,197 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultDockerMaxLineLength is the line length at which the Docker json-file log driver splits log lines (16 KiB).
+const DefaultDockerMaxLineLength = 16 * 1024
+
+// minDockerChunkSize is the space for the message which the fields must leave in every line, otherwise they are
+// truncated.
+const minDockerChunkSize = 64
+
+// minDockerFieldSize is the length below which oversize fields are removed instead of truncated.
+const minDockerFieldSize = 32
+
+// DockerTruncatedKey is the key of the field marking entries whose fields have been truncated or removed to fit into
+// the maximum line length.
+const DockerTruncatedKey = "fields_truncated"
+
+// DockerJSONFormatter is a logrus formatter that guarantees single-line JSON entries below a maximum line length.
+// Oversize messages are split into several entries, which are marked with the "part" and "continued" fields,
+// so that container runtimes do not mangle multi-line or giant entries. If the fields alone leave too little space
+// for the message, the largest fields are truncated (or removed) and the entry is marked with DockerTruncatedKey.
+// Only a maximum line length below the size of an entry without fields cannot be met.
+type DockerJSONFormatter struct {
+	Formatter     logrus.Formatter
+	MaxLineLength int
+}
+
+// Format formats the entry and splits it into several lines if it exceeds the maximum line length.
+func (f *DockerJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	line, err := f.Formatter.Format(entry)
+	if err != nil || f.MaxLineLength <= 0 || len(line) <= f.MaxLineLength {
+		return line, err
+	}
+
+	data, err := f.fitFields(entry)
+	if err != nil {
+		return nil, err
+	}
+	line, err = f.formatPart(entry, data, entry.Message, 0, false)
+	if err != nil || len(line) <= f.MaxLineLength {
+		return line, err // The truncated fields are sufficient
+	}
+
+	// Calculate the space that is left for the message in every line (with some space for the part number)
+	overhead, err := f.formatPart(entry, data, "", 1, true)
+	if err != nil {
+		return nil, err
+	}
+	chunkSize := max(f.MaxLineLength-len(overhead)-len("000"), 1)
+
+	var buf bytes.Buffer
+	message := entry.Message
+	for part := 1; message != ""; {
+		chunk := cutMessage(message, chunkSize)
+		partLine, err := f.formatPart(entry, data, chunk, part, len(chunk) < len(message))
+		if err != nil {
+			return nil, err
+		}
+
+		// Escaping may have increased the length, so retry with a smaller chunk if possible
+		if len(partLine) > f.MaxLineLength && chunkSize > 1 {
+			chunkSize /= 2
+			continue
+		}
+
+		buf.Write(partLine)
+		message = message[len(chunk):]
+		part++
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fitFields returns the fields of the entry, of which the largest are truncated (or removed) until a line with a
+// message chunk of minDockerChunkSize bytes fits into the maximum line length. The fields of the entry are not
+// modified.
+func (f *DockerJSONFormatter) fitFields(entry *logrus.Entry) (logrus.Fields, error) {
+	data := entry.Data
+	probe := strings.Repeat("x", min(len(entry.Message), minDockerChunkSize))
+	for copied := false; ; {
+		line, err := f.formatPart(entry, data, probe, 1, true)
+		if err != nil || len(line) <= f.MaxLineLength {
+			return data, err
+		}
+
+		key, value := largestField(data)
+		if key == "" {
+			return data, nil // Nothing left to truncate
+		}
+		if !copied {
+			data = make(logrus.Fields, len(entry.Data)+1)
+			for key, value := range entry.Data {
+				data[key] = value
+			}
+			copied = true
+		}
+
+		data[DockerTruncatedKey] = true
+		if len(value) < minDockerFieldSize {
+			delete(data, key)
+		} else {
+			data[key] = cutMessage(value, len(value)/2) + "…"
+		}
+	}
+}
+
+// largestField returns the key and the string representation of the largest field (by the length of its
+// representation), or an empty key if there is none.
+func largestField(data logrus.Fields) (string, string) {
+	largestKey, largestValue := "", ""
+	for key, value := range data {
+		if key == DockerTruncatedKey {
+			continue
+		}
+		formatted := fmt.Sprint(value)
+		if largestKey == "" || len(formatted) > len(largestValue) ||
+			(len(formatted) == len(largestValue) && key < largestKey) {
+			largestKey, largestValue = key, formatted
+		}
+	}
+	return largestKey, largestValue
+}
+
+// formatPart formats a copy of the entry with the fields and the given chunk of the message. The part markers are
+// added if the part number is positive.
+func (f *DockerJSONFormatter) formatPart(entry *logrus.Entry, fields logrus.Fields, chunk string, part int,
+	continued bool) ([]byte, error) {
+	data := make(logrus.Fields, len(fields)+2)
+	for key, value := range fields {
+		data[key] = value
+	}
+	if part > 0 {
+		data["part"] = part
+		data["continued"] = continued
+	}
+
+	partEntry := *entry
+	partEntry.Data = data
+	partEntry.Message = chunk
+	partEntry.Buffer = nil // The buffer of the original entry must not be reused by the inner formatter
+
+	return f.Formatter.Format(&partEntry)
+}
+
+// cutMessage returns the prefix of the message with at most size bytes without splitting a UTF-8 character. The
+// prefix contains at least one character (or byte of invalid UTF-8), so that splitting always makes progress.
+func cutMessage(message string, size int) string {
+	size = max(size, 1)
+	if len(message) <= size {
+		return message
+	}
+
+	// Step back to the start of the character (at most the length of a UTF-8 character)
+	for cut := size; cut > 0 && cut > size-utf8.UTFMax; cut-- {
+		if utf8.RuneStart(message[cut]) {
+			return message[:cut]
+		}
+	}
+	if _, n := utf8.DecodeRuneInString(message); n > size {
+		return message[:n] // The first character is longer than the size
+	}
+	return message[:size] // Invalid UTF-8 without a character to keep together
+}
+
+// SetDockerJSONMode enables or disables the output mode for the Docker json-file log driver. If maxLineLength is
+// positive, every entry is written as single-line JSON below that length, otherwise the plain JSON formatter is
+// restored. The mode is only available for the LogrusBackend.
+func SetDockerJSONMode(maxLineLength int) {
+	backend, ok := GetLogHelper().Backend().(*LogrusBackend)
+	if !ok {
+		return
+	}
+	logger := backend.Logger
+
+	// Unwrap the formatter if the mode has already been enabled before
+	formatter := logger.Formatter
+	if dockerFormatter, ok := formatter.(*DockerJSONFormatter); ok {
+		formatter = dockerFormatter.Formatter
+	}
+
+	if maxLineLength <= 0 {
+		logger.SetFormatter(formatter)
+		return
+	}
+
+	logger.SetFormatter(&DockerJSONFormatter{
+		Formatter:     formatter,
+		MaxLineLength: maxLineLength,
+	})
+}
@@ -0,0 +1,133 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// formatDocker formats an entry with the message and fields and returns the decoded lines, failing the test if a
+// line exceeds the maximum line length or is not valid JSON.
+func formatDocker(t *testing.T, maxLineLength int, msg string, fields logrus.Fields) []map[string]interface{} {
+	t.Helper()
+
+	formatter := &DockerJSONFormatter{Formatter: &logrus.JSONFormatter{}, MaxLineLength: maxLineLength}
+	entry := &logrus.Entry{Logger: logrus.New(), Data: fields, Time: time.Now(), Level: logrus.InfoLevel, Message: msg}
+
+	done := make(chan []byte)
+	go func() {
+		output, err := formatter.Format(entry)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- output
+	}()
+
+	var output []byte
+	select {
+	case output = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Format did not return")
+	}
+
+	var entries []map[string]interface{}
+	for _, line := range bytes.SplitAfter(output, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if len(line) > maxLineLength {
+			t.Errorf("Line of %d bytes exceeds the limit of %d", len(line), maxLineLength)
+		}
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(line, &decoded); err != nil {
+			t.Fatalf("Invalid line %q: %v", line, err)
+		}
+		entries = append(entries, decoded)
+	}
+	return entries
+}
+
+// joinParts returns the message reassembled from the parts.
+func joinParts(entries []map[string]interface{}) string {
+	var b strings.Builder
+	for _, entry := range entries {
+		b.WriteString(entry["msg"].(string))
+	}
+	return b.String()
+}
+
+func TestDockerFormatterKeepsShortEntries(t *testing.T) {
+	entries := formatDocker(t, 1024, "Short", logrus.Fields{"user": "alice"})
+	if len(entries) != 1 || entries[0]["part"] != nil {
+		t.Errorf("Short entry was split: %v", entries)
+	}
+}
+
+func TestDockerFormatterSplitsMessages(t *testing.T) {
+	msg := strings.Repeat("0123456789", 100) + strings.Repeat("é", 300)
+	entries := formatDocker(t, 256, msg, logrus.Fields{"user": "alice"})
+
+	if len(entries) < 2 {
+		t.Fatalf("Oversize message was not split: %v", entries)
+	}
+	for i, entry := range entries {
+		if entry["part"] != float64(i+1) || entry["continued"] != (i < len(entries)-1) || entry["user"] != "alice" {
+			t.Errorf("Unexpected markers or fields of part %d: %v", i+1, entry)
+		}
+		if !utf8.ValidString(entry["msg"].(string)) {
+			t.Errorf("Part %d splits a character", i+1)
+		}
+	}
+	if joinParts(entries) != msg {
+		t.Error("Parts do not reassemble the message")
+	}
+}
+
+func TestDockerFormatterSplitsInvalidUTF8(t *testing.T) {
+	msg := strings.Repeat("\x80", 2000) // Continuation bytes without a character start
+	entries := formatDocker(t, 256, msg, nil)
+	if len(entries) < 2 {
+		t.Errorf("Oversize message was not split: %v", entries)
+	}
+}
+
+func TestDockerFormatterTruncatesOversizeFields(t *testing.T) {
+	fields := logrus.Fields{"payload": strings.Repeat("p", 1000), "user": "alice"}
+	for _, msg := range []string{"", "Short", strings.Repeat("m", 1000)} {
+		entries := formatDocker(t, 256, msg, fields)
+		for _, entry := range entries {
+			if entry[DockerTruncatedKey] != true || entry["user"] != "alice" {
+				t.Errorf("Fields not truncated as expected: %v", entry)
+			}
+		}
+		if joinParts(entries) != msg {
+			t.Errorf("Message %q was not kept", msg)
+		}
+	}
+	if len(fields["payload"].(string)) != 1000 {
+		t.Error("Fields of the entry were modified")
+	}
+}
+
+func TestCutMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		size    int
+		want    string
+	}{
+		{"abc", 5, "abc"},
+		{"abcdef", 3, "abc"},
+		{"aé", 2, "a"},              // é is two bytes
+		{"€uro", 2, "€"},            // The first character is longer than the size
+		{"\x80\x80\x80", 0, "\x80"}, // Progress on invalid UTF-8
+	}
+	for _, test := range tests {
+		if got := cutMessage(test.message, test.size); got != test.want {
+			t.Errorf("cutMessage(%q, %d) = %q, want %q", test.message, test.size, got, test.want)
+		}
+	}
+}
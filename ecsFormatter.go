@@ -0,0 +1,67 @@
+package FlowWatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// ecsVersion is the version of the Elastic Common Schema the ECSFormatter complies with.
+const ecsVersion = "8.11.0"
+
+// ECSFormatter is a logrus formatter writing JSON entries according to the Elastic Common Schema, so that the logs
+// are searchable in Elastic and Kibana without an ingest pipeline remapping the field names. The structured fields
+// are added unchanged.
+type ECSFormatter struct{}
+
+// Format formats the entry as ECS JSON line.
+func (f *ECSFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(map[string]interface{}, len(entry.Data)+8)
+	for key, value := range entry.Data {
+		switch key {
+		case ErrorKey, StackKey, "file", "line", TraceIDKey, SpanIDKey:
+			continue // Mapped to the ECS fields below
+		case LoggerKey:
+			data["log.logger"] = value
+		default:
+			if err, ok := value.(error); ok {
+				value = err.Error() // Errors are not serialized by encoding/json
+			}
+			data[key] = value
+		}
+	}
+
+	data["@timestamp"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	data["log.level"] = entry.Level.String()
+	data["message"] = entry.Message
+	data["ecs.version"] = ecsVersion
+
+	if file, ok := entry.Data["file"]; ok {
+		data["log.origin.file.name"] = file
+		data["log.origin.file.line"] = entry.Data["line"]
+	}
+
+	if err, ok := entry.Data[ErrorKey].(error); ok {
+		data["error.message"] = err.Error()
+		data["error.type"] = fmt.Sprintf("%T", errors.Cause(err))
+		if stack, ok := entry.Data[StackKey].(string); ok {
+			data["error.stack_trace"] = stack
+		}
+	}
+
+	if entry.Context != nil {
+		if spanContext := trace.SpanContextFromContext(entry.Context); spanContext.IsValid() {
+			data["trace.id"] = spanContext.TraceID().String()
+			data["span.id"] = spanContext.SpanID().String()
+		}
+	}
+
+	line, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to marshal the ECS entry")
+	}
+	return append(line, '\n'), nil
+}
@@ -0,0 +1,168 @@
+package FlowWatch
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+const (
+	// ErrorKey is the key of the field containing an error attached with WithError.
+	ErrorKey = "error"
+
+	// StackKey is the key of the field containing the stack trace of an error attached with WithError.
+	StackKey = "stack"
+
+	// TraceIDKey is the key of the field containing the trace ID of the context (refer to LogrusTraceContextHook).
+	TraceIDKey = "trace_id"
+
+	// SpanIDKey is the key of the field containing the span ID of the context (refer to LogrusTraceContextHook).
+	SpanIDKey = "span_id"
+)
+
+// Fields is a set of structured key/value pairs, which are added to the log entry and to the span event.
+type Fields map[string]interface{}
+
+// Entry is a log entry with structured fields, which is created by WithFields or WithField and can be chained.
+type Entry struct {
+	lh     *LogHelper
+	ctx    context.Context
+	fields Fields
+}
+
+// WithFields returns an entry with the given structured fields attached.
+func (lh *LogHelper) WithFields(ctx context.Context, fields Fields) *Entry {
+	return (&Entry{lh: lh, ctx: ctx}).WithFields(fields)
+}
+
+// WithField returns an entry with the given structured field attached.
+func (lh *LogHelper) WithField(ctx context.Context, key string, value interface{}) *Entry {
+	return (&Entry{lh: lh, ctx: ctx}).WithField(key, value)
+}
+
+// WithFields returns a copy of the entry with the given structured fields attached.
+func (e *Entry) WithFields(fields Fields) *Entry {
+	merged := make(Fields, len(e.fields)+len(fields))
+	for key, value := range e.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+
+	return &Entry{lh: e.lh, ctx: e.ctx, fields: merged}
+}
+
+// WithField returns a copy of the entry with the given structured field attached.
+func (e *Entry) WithField(key string, value interface{}) *Entry {
+	return e.WithFields(Fields{key: value})
+}
+
+// Trace logs a message with the fields of the entry at the trace level.
+func (e *Entry) Trace(args ...interface{}) {
+	e.lh.log(e.ctx, Trace, e.fields, args...)
+}
+
+// Debug logs a message with the fields of the entry at the debug level.
+func (e *Entry) Debug(args ...interface{}) {
+	e.lh.log(e.ctx, Debug, e.fields, args...)
+}
+
+// Info logs a message with the fields of the entry at the info level.
+func (e *Entry) Info(args ...interface{}) {
+	e.lh.log(e.ctx, Info, e.fields, args...)
+}
+
+// Warn logs a message with the fields of the entry at the warning level.
+func (e *Entry) Warn(args ...interface{}) {
+	e.lh.log(e.ctx, Warn, e.fields, args...)
+}
+
+// Error logs a message with the fields of the entry at the error level.
+func (e *Entry) Error(args ...interface{}) {
+	e.lh.log(e.ctx, Error, e.fields, args...)
+}
+
+// Fatal logs a message with the fields of the entry at the fatal level and terminates the program afterward.
+func (e *Entry) Fatal(args ...interface{}) {
+	e.lh.log(e.ctx, Fatal, e.fields, args...)
+}
+
+// Panic logs a message with the fields of the entry at the panic level and panics afterward.
+func (e *Entry) Panic(args ...interface{}) {
+	e.lh.log(e.ctx, Panic, e.fields, args...)
+}
+
+// Tracef logs a formatted message with the fields of the entry at the trace level.
+func (e *Entry) Tracef(format string, args ...interface{}) {
+	e.lh.logf(e.ctx, Trace, e.fields, format, args...)
+}
+
+// Debugf logs a formatted message with the fields of the entry at the debug level.
+func (e *Entry) Debugf(format string, args ...interface{}) {
+	e.lh.logf(e.ctx, Debug, e.fields, format, args...)
+}
+
+// Infof logs a formatted message with the fields of the entry at the info level.
+func (e *Entry) Infof(format string, args ...interface{}) {
+	e.lh.logf(e.ctx, Info, e.fields, format, args...)
+}
+
+// Warnf logs a formatted message with the fields of the entry at the warning level.
+func (e *Entry) Warnf(format string, args ...interface{}) {
+	e.lh.logf(e.ctx, Warn, e.fields, format, args...)
+}
+
+// Errorf logs a formatted message with the fields of the entry at the error level.
+func (e *Entry) Errorf(format string, args ...interface{}) {
+	e.lh.logf(e.ctx, Error, e.fields, format, args...)
+}
+
+// Fatalf logs a formatted message with the fields of the entry at the fatal level and terminates the program afterward.
+func (e *Entry) Fatalf(format string, args ...interface{}) {
+	e.lh.logf(e.ctx, Fatal, e.fields, format, args...)
+}
+
+// Panicf logs a formatted message with the fields of the entry at the panic level and panics afterward.
+func (e *Entry) Panicf(format string, args ...interface{}) {
+	e.lh.logf(e.ctx, Panic, e.fields, format, args...)
+}
+
+// stackTracer is implemented by errors of github.com/pkg/errors, which carry the stack trace of their creation.
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+// WithError returns an entry with the error and its stack trace (if available) attached. When the entry is logged at
+// warning level or higher, the error is additionally recorded on the span from the context.
+func (lh *LogHelper) WithError(ctx context.Context, err error) *Entry {
+	return (&Entry{lh: lh, ctx: ctx}).WithError(err)
+}
+
+// WithError returns a copy of the entry with the error and its stack trace (if available) attached.
+func (e *Entry) WithError(err error) *Entry {
+	fields := Fields{ErrorKey: err}
+	if stack := errorStack(err); stack != "" {
+		fields[StackKey] = stack
+	}
+
+	return e.WithFields(fields)
+}
+
+// errorStack returns the formatted stack trace of the deepest error in the chain, which provides a stack trace.
+func errorStack(err error) string {
+	var stack errors.StackTrace
+	for err != nil {
+		if tracer, ok := err.(stackTracer); ok {
+			stack = tracer.StackTrace()
+		}
+		err = stderrors.Unwrap(err)
+	}
+
+	if stack == nil {
+		return ""
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%+v", stack), "\n")
+}
@@ -0,0 +1,69 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// entryFields are the fields of an entry during the processing, which is shared by the logrus hooks and the other
+// backends. The fields of the caller are copied before the first change, so that reused entries are not modified,
+// while the logrus hooks change the data of their entry directly.
+type entryFields struct {
+	data   Fields
+	copied bool
+}
+
+// hookFields returns the fields of the logrus entry, which are changed directly.
+func hookFields(entry *logrus.Entry) *entryFields {
+	return &entryFields{data: Fields(entry.Data), copied: true}
+}
+
+// set sets the field, copying the fields before the first change.
+func (f *entryFields) set(key string, value interface{}) {
+	if !f.copied {
+		data := make(Fields, len(f.data)+4) // Space for the caller and the trace context
+		for k, v := range f.data {
+			data[k] = v
+		}
+		f.data, f.copied = data, true
+	}
+	f.data[key] = value
+}
+
+// processEntry applies the processing of the default logrus hooks (refer to DefaultHooks) to an entry of the other
+// backends, so that all backends write the same entries: the caller, the trace context, the replacement of large
+// binary fields and the size guard. It returns the fields, which are a copy if any field has changed, and the message,
+// which may have been truncated.
+func processEntry(ctx context.Context, level Level, fields Fields, msg string) (Fields, string) {
+	data := &entryFields{data: fields}
+	if level != Info { // runtime.Caller is expensive, refer to the LogrusContextHook
+		addCaller(ctx, data)
+	}
+	addTraceContext(ctx, data)
+	replaceBlobs(ctx, data)
+	msg = guardEntrySize(data, msg)
+	if level.AtLeast(Warn) {
+		markUnexportedEvent(ctx, data)
+	}
+
+	return data.data, msg
+}
+
+// exportEntry exports an entry processed by processEntry like the LogrusOtelHook and the LogrusOtelShutdownHook. It has
+// to be called before the entry is written, since the backends panic after writing panic entries.
+func exportEntry(ctx context.Context, level Level, msg string, data Fields, t time.Time) {
+	if level.AtLeast(Warn) {
+		exportLogEvent(ctx, level, msg, data, t)
+	}
+	shutdownOnTermination(ctx, level)
+}
+
+// shutdownOnTermination shuts down the OpenTelemetry connection before the program terminates after fatal and panic
+// entries. Recovered panics are skipped, since the program continues (refer to RecoverAndLog).
+func shutdownOnTermination(ctx context.Context, level Level) {
+	if level.AtLeast(Fatal) && !isRecoveredPanic(ctx) {
+		otelHelper.Shutdown()
+	}
+}
@@ -0,0 +1,79 @@
+package FlowWatch
+
+import (
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+const (
+	// LogEventName is the name of the span events created by the DefaultEventNamer.
+	LogEventName = "log"
+
+	// ExceptionEventName is the name of the span event for exceptions according to the OTel semantic conventions.
+	ExceptionEventName = "exception"
+
+	// maxEventNameLength is the maximum length of the event names derived by the SemanticEventNamer.
+	maxEventNameLength = 64
+)
+
+// EventNamer returns the name of the span event for a log entry. If it returns ExceptionEventName for an entry with
+// an attached error, the entry is merged into the exception event recorded for the error.
+type EventNamer func(level Level, msg string, fields map[string]interface{}) string
+
+var eventNamer atomic.Pointer[EventNamer]
+
+// SetEventNamer configures the naming of the span events (default: DefaultEventNamer). Pass nil to restore the default.
+func SetEventNamer(namer EventNamer) {
+	if namer == nil {
+		eventNamer.Store(nil)
+		return
+	}
+	eventNamer.Store(&namer)
+}
+
+// getEventNamer returns the configured event namer.
+func getEventNamer() EventNamer {
+	if namer := eventNamer.Load(); namer != nil {
+		return *namer
+	}
+	return DefaultEventNamer
+}
+
+// DefaultEventNamer names all span events "log".
+func DefaultEventNamer(Level, string, map[string]interface{}) string {
+	return LogEventName
+}
+
+// SemanticEventNamer names the events of entries with an attached error "exception" following the OTel semantic
+// conventions and derives the names of all other events from the message template, i.e. the message with its
+// variable parts (numbers, IDs and quoted values) replaced by placeholders, so that backend queries can distinguish
+// the event types.
+func SemanticEventNamer(_ Level, msg string, fields map[string]interface{}) string {
+	if _, ok := fields[ErrorKey].(error); ok {
+		return ExceptionEventName
+	}
+
+	words := strings.Fields(msg)
+	for i, word := range words {
+		if isVariableWord(word) {
+			words[i] = "{}"
+		}
+	}
+
+	name := strings.Join(words, " ")
+	if name == "" {
+		return LogEventName
+	}
+	return cutMessage(name, maxEventNameLength)
+}
+
+// isVariableWord reports whether the word of a message is likely a variable part (contains digits or is quoted).
+func isVariableWord(word string) bool {
+	if strings.ContainsFunc(word, unicode.IsDigit) {
+		return true
+	}
+
+	trimmed := strings.TrimRight(word, ".,;:!?")
+	return len(trimmed) >= 2 && strings.ContainsRune(`"'`+"`", rune(trimmed[0])) && trimmed[len(trimmed)-1] == trimmed[0]
+}
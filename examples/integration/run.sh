@@ -0,0 +1,77 @@
+#!/usr/bin/env bash
+# Integration harness: starts the dockerized collector, runs the example service against it (once in plaintext and
+# once over TLS) and verifies that spans, log events and recorded errors were exported and that the shutdown flushed
+# the remaining spans.
+set -euo pipefail
+
+cd "$(dirname "$0")"
+ROOT="$(cd ../.. && pwd)"
+
+mkdir -p output/certs
+rm -f output/traces.json
+chmod 777 output # The collector does not run as the current user
+
+# Issue a CA and a server certificate for localhost, which the service trusts via OTEL_EXPORTER_OTLP_CERTIFICATE
+openssl req -x509 -newkey rsa:2048 -nodes -days 1 -subj "/CN=FlowWatch Integration CA" \
+  -keyout output/certs/ca-key.pem -out output/certs/ca.pem 2>/dev/null
+openssl req -newkey rsa:2048 -nodes -subj "/CN=localhost" \
+  -keyout output/certs/server-key.pem -out output/certs/server.csr 2>/dev/null
+openssl x509 -req -days 1 -in output/certs/server.csr -CA output/certs/ca.pem -CAkey output/certs/ca-key.pem \
+  -CAcreateserial -extfile <(printf "subjectAltName=DNS:localhost,IP:127.0.0.1") \
+  -out output/certs/server.pem 2>/dev/null
+chmod 644 output/certs/*.pem # The collector does not run as the current user
+
+cleanup() {
+  docker compose down --volumes >/dev/null 2>&1 || true
+}
+trap cleanup EXIT
+
+docker compose up --detach --wait
+
+(cd "$ROOT" && go build -o "$OLDPWD/output/service" ./examples/service)
+
+# run_service runs the example service with the given name against the collector, sends two requests and stops it
+run_service() {
+  local name="$1" collector="$2" tls="$3"
+
+  OTEL_SERVICE_NAME="$name" \
+  OTEL_COLLECTOR_URL="$collector" \
+  OTEL_SUPPORT_TLS="$tls" \
+  OTEL_EXPORTER_OTLP_CERTIFICATE="$PWD/output/certs/ca.pem" \
+  EXAMPLE_ADDR="localhost:18080" \
+    ./output/service &
+  local pid=$!
+
+  for _ in $(seq 1 50); do
+    curl --silent --output /dev/null "http://localhost:18080/order?id=42" && break
+    sleep 0.1
+  done
+  curl --silent --output /dev/null "http://localhost:18080/order?id=7"
+
+  # Stop the service, the remaining spans must be flushed by the shutdown
+  kill -TERM "$pid"
+  wait "$pid"
+}
+
+run_service "FlowWatchIntegration" "localhost:4317" false
+run_service "FlowWatchIntegrationTLS" "localhost:4319" true
+
+fail() {
+  echo "FAIL: $1" >&2
+  exit 1
+}
+
+# Wait for the collector to write the batches of both services
+for _ in $(seq 1 50); do
+  grep -q '"FlowWatchIntegrationTLS"' output/traces.json 2>/dev/null && break
+  sleep 0.2
+done
+
+grep -q '"FlowWatchIntegration"' output/traces.json || fail "service name missing"
+grep -q '"FlowWatchIntegrationTLS"' output/traces.json || fail "spans exported over TLS missing"
+grep -q '"HandleOrder"' output/traces.json || fail "request span missing"
+grep -q '"FindOrder"' output/traces.json || fail "child span missing"
+grep -q '"Order lookup failed"' output/traces.json || fail "log event missing"
+grep -q '"exception"' output/traces.json || fail "recorded error missing"
+
+echo "OK: spans, log events and errors were exported (plaintext and TLS)"
@@ -0,0 +1,86 @@
+// Command service is an example HTTP service showing the correct wiring of FlowWatch: setup and graceful shutdown of
+// the OpenTelemetry connection, spans per request and logs which are attached to the spans.
+package main
+
+import (
+	"context"
+	"github.com/LucaSchmitz2003/FlowWatch"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+var (
+	OrderNotFoundError = errors.New("Order not found")
+	tracer             = otel.Tracer("ExampleService")
+	logger             = FlowWatch.GetLogHelper()
+)
+
+// findOrder simulates a lookup which fails for unknown orders.
+func findOrder(ctx context.Context, id string) error {
+	_, span := tracer.Start(ctx, "FindOrder")
+	defer span.End()
+
+	if id != "42" {
+		return errors.Wrapf(OrderNotFoundError, "Failed to find order %s", id)
+	}
+	return nil
+}
+
+// handleOrder handles the order requests and logs failed lookups on the request span.
+func handleOrder(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "HandleOrder")
+	defer span.End()
+
+	id := r.URL.Query().Get("id")
+	logger.WithField(ctx, "order", id).Info("Order requested")
+
+	if err := findOrder(ctx, id); err != nil {
+		logger.WithError(ctx, err).Warn("Order lookup failed")
+		http.Error(w, "order not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func main() {
+	ctx := context.Background()
+
+	// Initialize the OpenTelemetry SDK connection to the backend
+	otelHelper.SetupOtelHelper()
+	defer otelHelper.Shutdown() // Flush the remaining spans when the service stops
+
+	addr := os.Getenv("EXAMPLE_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/order", handleOrder)
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		logger.Infof(ctx, "Listening on %s", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.WithError(ctx, err).Error("Server failed")
+		}
+	}()
+
+	// Wait for the termination signal and shut down gracefully
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.WithError(ctx, err).Warn("Failed to shut down the server gracefully")
+	}
+	logger.Info(ctx, "Service stopped")
+}
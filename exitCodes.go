@@ -0,0 +1,84 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+// Exit codes of the fatal error categories, so that orchestrators and scripts can react to the failure class.
+const (
+	ExitCodeFailure    = 1 // Default for fatal entries without a category
+	ExitCodeConfig     = 2
+	ExitCodeDependency = 3
+	ExitCodePanic      = 4
+)
+
+// Fatal error categories. Errors wrapping a category terminate the program with the exit code of the category
+// when they are attached to a fatal entry (refer to ReportFatal).
+var (
+	ConfigError     = errors.New("Invalid configuration")
+	DependencyError = errors.New("Dependency unreachable")
+	PanicError      = errors.New("Unrecovered panic")
+)
+
+// exitCategory associates an error category with its exit code.
+type exitCategory struct {
+	category error
+	code     int
+}
+
+var (
+	exitCategoriesMu sync.RWMutex
+	exitCategories   = []exitCategory{
+		{category: ConfigError, code: ExitCodeConfig},
+		{category: DependencyError, code: ExitCodeDependency},
+		{category: PanicError, code: ExitCodePanic},
+	}
+)
+
+// RegisterExitCode associates the error category with the exit code or updates the code of a known category.
+func RegisterExitCode(category error, code int) {
+	exitCategoriesMu.Lock()
+	defer exitCategoriesMu.Unlock()
+
+	for i := range exitCategories {
+		if exitCategories[i].category == category {
+			exitCategories[i].code = code
+			return
+		}
+	}
+	exitCategories = append(exitCategories, exitCategory{category: category, code: code})
+}
+
+// ExitCode returns the exit code of the first registered category the error wraps or ExitCodeFailure.
+func ExitCode(err error) int {
+	exitCategoriesMu.RLock()
+	defer exitCategoriesMu.RUnlock()
+
+	for _, category := range exitCategories {
+		if errors.Is(err, category.category) {
+			return category.code
+		}
+	}
+	return ExitCodeFailure
+}
+
+// ReportFatal logs the error at the fatal level and terminates the program with the exit code of its category, e.g.
+// lh.ReportFatal(ctx, errors.Wrap(FlowWatch.ConfigError, "OTEL_SERVICE_NAME is missing")) exits with code 2.
+func (lh *LogHelper) ReportFatal(ctx context.Context, err error) {
+	lh.WithError(ctx, err).Fatal(err)
+}
+
+// ReportFatal reports the error with the shared LogHelper instance (refer to LogHelper.ReportFatal).
+func ReportFatal(ctx context.Context, err error) {
+	GetLogHelper().WithError(ctx, err).Fatal(err)
+}
+
+// fatalExitCode returns the exit code for a fatal entry with the fields.
+func fatalExitCode(fields Fields) int {
+	if err, ok := fields[ErrorKey].(error); ok {
+		return ExitCode(err)
+	}
+	return ExitCodeFailure
+}
@@ -0,0 +1,102 @@
+package FlowWatch
+
+import (
+	"github.com/sirupsen/logrus"
+	"path"
+)
+
+// FieldFilter selects the fields of the entries sent to a sink, since destinations have different sensitivity
+// requirements (e.g. never send user_email to third-party sinks). The names may contain path.Match patterns
+// (e.g. "http.*").
+type FieldFilter struct {
+	Allow []string // Fields that are kept, all fields are kept if empty
+	Deny  []string // Fields that are dropped, applied after the allow-list
+}
+
+// apply returns the fields passing the filter. The fields are only copied if a field is dropped.
+func (f FieldFilter) apply(data logrus.Fields) logrus.Fields {
+	var filtered logrus.Fields
+	for key := range data {
+		if f.keeps(key) {
+			continue
+		}
+		if filtered == nil {
+			filtered = make(logrus.Fields, len(data))
+			for k, v := range data {
+				filtered[k] = v
+			}
+		}
+		delete(filtered, key)
+	}
+
+	if filtered == nil {
+		return data
+	}
+	return filtered
+}
+
+// keeps reports whether the field passes the filter.
+func (f FieldFilter) keeps(key string) bool {
+	if len(f.Allow) > 0 && !matchesAny(f.Allow, key) {
+		return false
+	}
+	return !matchesAny(f.Deny, key)
+}
+
+// matchesAny reports whether the key matches one of the patterns.
+func matchesAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// filteredEntry returns a copy of the entry with the fields passing the filter.
+func filteredEntry(entry *logrus.Entry, filter FieldFilter) *logrus.Entry {
+	filtered := *entry
+	filtered.Data = filter.apply(entry.Data)
+	return &filtered
+}
+
+// filteredHook is a hook receiving only the fields passing the filter.
+type filteredHook struct {
+	hook   logrus.Hook
+	filter FieldFilter
+}
+
+// FilterHook wraps the hook of a sink (e.g. the LogrusSentryHook or the LogrusOtelHook), so that it only receives the
+// fields passing the filter. Hooks adding fields to the entry (e.g. the LogrusContextHook) must not be wrapped.
+func FilterHook(hook logrus.Hook, filter FieldFilter) logrus.Hook {
+	return filteredHook{hook: hook, filter: filter}
+}
+
+// Levels returns the levels of the wrapped hook.
+func (hook filteredHook) Levels() []logrus.Level {
+	return hook.hook.Levels()
+}
+
+// Fire passes a copy of the entry with the filtered fields to the wrapped hook.
+func (hook filteredHook) Fire(entry *logrus.Entry) error {
+	return hook.hook.Fire(filteredEntry(entry, hook.filter))
+}
+
+// filteredFormatter is a formatter writing only the fields passing the filter.
+type filteredFormatter struct {
+	formatter logrus.Formatter
+	filter    FieldFilter
+}
+
+// Format formats a copy of the entry with the filtered fields.
+func (f filteredFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return f.formatter.Format(filteredEntry(entry, f.filter))
+}
+
+// WithFieldFilter filters the fields written to the output (e.g. drop the stack from the console but keep it for
+// OpenTelemetry). The hooks of other sinks are filtered with FilterHook.
+func WithFieldFilter(filter FieldFilter) Option {
+	return func(o *options) {
+		o.fieldFilter = &filter
+	}
+}
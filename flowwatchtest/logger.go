@@ -0,0 +1,178 @@
+// Package flowwatchtest provides helpers for using FlowWatch in tests.
+package flowwatchtest
+
+import (
+	"context"
+	"fmt"
+	"github.com/LucaSchmitz2003/FlowWatch"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// options holds the configuration of a test logger created by Logger.
+type options struct {
+	level       FlowWatch.Level
+	allowErrors bool
+}
+
+// Option configures a test logger created by Logger.
+type Option func(*options)
+
+// WithLevel sets the log level of the test logger (default: Trace).
+func WithLevel(level FlowWatch.Level) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
+
+// AllowErrors keeps the test logger from failing the test on error entries (e.g. in tests of error paths). Fatal
+// entries still stop the test.
+func AllowErrors() Option {
+	return func(o *options) {
+		o.allowErrors = true
+	}
+}
+
+// Logger returns a LogHelper writing all entries to t.Log with the location of the log call and a level prefix (the
+// location reported by the testing package points into this package, since the frames of FlowWatch cannot be marked
+// with t.Helper). Error entries fail the test unless AllowErrors is set. Fatal entries fail the test instead of
+// terminating the test binary: on the goroutine of the test, they also stop it like t.FailNow. Other goroutines
+// continue after fatal entries, since the testing package only allows stopping the goroutine of the test.
+func Logger(t testing.TB, opts ...Option) *FlowWatch.LogHelper {
+	t.Helper()
+
+	o := &options{level: FlowWatch.Trace}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	backend := &testBackend{t: t, allowErrors: o.allowErrors, state: &testState{goroutine: goroutineID()}}
+	t.Cleanup(func() {
+		backend.state.mu.Lock()
+		defer backend.state.mu.Unlock()
+
+		backend.state.done = true // t.Log panics after the test has completed
+	})
+
+	return FlowWatch.NewLogHelper(
+		FlowWatch.WithBackend(backend),
+		FlowWatch.WithLevel(o.level),
+		FlowWatch.WithExitFunc(backend.exit),
+	)
+}
+
+// testState is shared by a test backend and its children.
+type testState struct {
+	mu        sync.Mutex
+	done      bool   // Whether the test has completed
+	goroutine uint64 // ID of the goroutine which created the logger (usually the goroutine of the test)
+}
+
+// testBackend is the FlowWatch.Backend writing to the log of a test.
+type testBackend struct {
+	t           testing.TB
+	level       atomic.Uint32
+	allowErrors bool
+	state       *testState
+}
+
+// Log writes the entry to the log of the test and fails the test on unexpected error entries.
+func (b *testBackend) Log(level FlowWatch.Level, _ context.Context, fields FlowWatch.Fields, msg string) {
+	if !b.IsLevelEnabled(level) {
+		return
+	}
+
+	location := "???:1"
+	if file, line, ok := FlowWatch.Caller(); ok {
+		location = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+
+	b.state.mu.Lock()
+	if !b.state.done {
+		line := fmt.Sprintf("%s: [%s] %s%s", location, strings.ToUpper(level.String()), msg, formatFields(fields))
+		if level.Severity() > FlowWatch.Error.Severity() || (level == FlowWatch.Error && !b.allowErrors) {
+			b.t.Error(line)
+		} else {
+			b.t.Log(line)
+		}
+	}
+	b.state.mu.Unlock()
+
+	if level == FlowWatch.Panic {
+		panic(msg)
+	}
+}
+
+// exit fails the test after a fatal entry and stops it if it is called on the goroutine of the test (calling
+// t.FailNow on other goroutines is not allowed).
+func (b *testBackend) exit(code int) {
+	b.state.mu.Lock()
+	done := b.state.done
+	b.state.mu.Unlock()
+	if done {
+		return
+	}
+
+	b.t.Errorf("Fatal entry (exit code %d)", code)
+	if goroutineID() == b.state.goroutine {
+		runtime.Goexit() // Runs the deferred calls and the cleanup like t.FailNow
+	}
+}
+
+// goroutineID returns the ID of the current goroutine, parsed from the header of its stack trace.
+func goroutineID() uint64 {
+	var buf [64]byte
+	header := strings.TrimPrefix(string(buf[:runtime.Stack(buf[:], false)]), "goroutine ")
+	header, _, _ = strings.Cut(header, " ")
+	id, _ := strconv.ParseUint(header, 10, 64)
+	return id
+}
+
+// IsLevelEnabled reports whether entries at the level are written.
+func (b *testBackend) IsLevelEnabled(level FlowWatch.Level) bool {
+	return level.AtLeast(b.GetLevel())
+}
+
+// GetLevel returns the current log level.
+func (b *testBackend) GetLevel() FlowWatch.Level {
+	return FlowWatch.Level(b.level.Load())
+}
+
+// SetLevel updates the log level.
+func (b *testBackend) SetLevel(level FlowWatch.Level) {
+	b.level.Store(uint32(level))
+}
+
+// Child returns a backend writing to the same test with its own log level.
+func (b *testBackend) Child() FlowWatch.Backend {
+	child := &testBackend{t: b.t, allowErrors: b.allowErrors, state: b.state}
+	child.SetLevel(b.GetLevel())
+
+	return child
+}
+
+// Flush is a no-op, since the entries are written synchronously.
+func (b *testBackend) Flush() error {
+	return nil
+}
+
+// formatFields formats the fields as sorted key=value pairs.
+func formatFields(fields FlowWatch.Fields) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%v", key, fields[key])
+	}
+	return b.String()
+}
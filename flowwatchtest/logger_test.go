@@ -0,0 +1,150 @@
+package flowwatchtest_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/LucaSchmitz2003/FlowWatch"
+	"github.com/LucaSchmitz2003/FlowWatch/flowwatchtest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recorder is a testing.TB recording the log and the failures instead of writing them to the test.
+type recorder struct {
+	testing.TB
+	mu       sync.Mutex
+	logs     []string
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Log(args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logs = append(r.logs, fmt.Sprint(args...))
+}
+
+func (r *recorder) Error(args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprint(args...))
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.Error(fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+// finish runs the cleanup functions like the testing package after the test has completed.
+func (r *recorder) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestLoggerWritesToTheTestLog(t *testing.T) {
+	r := &recorder{TB: t}
+	lh := flowwatchtest.Logger(r)
+
+	lh.WithFields(context.Background(), FlowWatch.Fields{"b": 2, "a": 1}).Info("ready")
+
+	if len(r.logs) != 1 || len(r.errors) != 0 {
+		t.Fatalf("logs = %q, errors = %q", r.logs, r.errors)
+	}
+	if !strings.HasPrefix(r.logs[0], "logger_test.go:") || !strings.HasSuffix(r.logs[0], ": [INFO] ready a=1 b=2") {
+		t.Errorf("unexpected line %q", r.logs[0])
+	}
+}
+
+func TestLoggerFailsOnErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []flowwatchtest.Option
+		wantErrors int
+	}{
+		{name: "default", wantErrors: 1},
+		{name: "allow errors", opts: []flowwatchtest.Option{flowwatchtest.AllowErrors()}, wantErrors: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			lh := flowwatchtest.Logger(r, tt.opts...)
+
+			lh.Error(context.Background(), "failed")
+
+			if len(r.errors) != tt.wantErrors || len(r.logs)+len(r.errors) != 1 {
+				t.Errorf("logs = %q, errors = %q", r.logs, r.errors)
+			}
+		})
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	r := &recorder{TB: t}
+	lh := flowwatchtest.Logger(r, flowwatchtest.WithLevel(FlowWatch.Warn))
+
+	lh.Info(context.Background(), "hidden")
+	lh.Warn(context.Background(), "shown")
+
+	if len(r.logs) != 1 || !strings.Contains(r.logs[0], "[WARN] shown") {
+		t.Errorf("logs = %q", r.logs)
+	}
+}
+
+func TestLoggerFatalStopsTheTestGoroutine(t *testing.T) {
+	r := &recorder{TB: t}
+	done := make(chan bool)
+
+	go func() {
+		continued := false
+		defer func() { done <- continued }()
+
+		lh := flowwatchtest.Logger(r) // Created on the goroutine acting as the goroutine of the test
+		lh.Fatal(context.Background(), "fatal")
+		continued = true
+	}()
+
+	if <-done {
+		t.Error("the goroutine of the test continued after a fatal entry")
+	}
+	if len(r.errors) != 2 { // The entry and the exit
+		t.Errorf("errors = %q", r.errors)
+	}
+}
+
+func TestLoggerFatalOnOtherGoroutines(t *testing.T) {
+	r := &recorder{TB: t}
+	lh := flowwatchtest.Logger(r)
+
+	continued := make(chan bool)
+	go func() {
+		lh.Fatal(context.Background(), "fatal")
+		continued <- true
+	}()
+
+	if !<-continued {
+		t.Error("other goroutines must continue after a fatal entry")
+	}
+	if len(r.errors) != 2 {
+		t.Errorf("errors = %q", r.errors)
+	}
+}
+
+func TestLoggerAfterTheTest(t *testing.T) {
+	r := &recorder{TB: t}
+	lh := flowwatchtest.Logger(r)
+	r.finish()
+
+	lh.Error(context.Background(), "late")
+
+	if len(r.logs) != 0 || len(r.errors) != 0 {
+		t.Errorf("entries written after the test: logs = %q, errors = %q", r.logs, r.errors)
+	}
+}
@@ -0,0 +1,52 @@
+package flowwatchtest
+
+import (
+	"context"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+// TestFailedError is recorded on the span of a failed test.
+var TestFailedError = errors.New("Test failed")
+
+var tracer = otel.Tracer("FlowWatch/flowwatchtest")
+
+// Main sets up the OpenTelemetry connection, runs the tests and exports the spans before returning the exit code, so
+// that the spans of Span can be analyzed in the tracing backend:
+//
+//	func TestMain(m *testing.M) { os.Exit(flowwatchtest.Main(m)) }
+func Main(m *testing.M) int {
+	otelHelper.SetupOtelHelper()
+	defer otelHelper.Shutdown()
+
+	return m.Run()
+}
+
+// Span starts a span named after the test, which is ended when the test and its subtests complete. Failed tests mark
+// the span as failed and the status of the test (passed, failed or skipped) is recorded on the span.
+func Span(t testing.TB) context.Context {
+	t.Helper()
+
+	ctx, span := tracer.Start(context.Background(), t.Name(),
+		trace.WithAttributes(attribute.String("test.name", t.Name())))
+	t.Cleanup(func() {
+		status := "passed"
+		switch {
+		case t.Failed():
+			status = "failed"
+			span.RecordError(errors.Wrap(TestFailedError, t.Name()))
+			span.SetStatus(codes.Error, TestFailedError.Error())
+		case t.Skipped():
+			status = "skipped"
+		}
+		span.SetAttributes(attribute.String("test.status", status))
+		span.End()
+	})
+
+	return ctx
+}
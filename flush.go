@@ -0,0 +1,60 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// defaultFlushTimeout bounds the flush of the telemetry by the helpers, e.g. after a recovered panic.
+const defaultFlushTimeout = 5 * time.Second
+
+// Flush writes the buffered entries of the backend and exports the pending spans, so that short-lived programs like
+// CLI tools and batch jobs do not lose any telemetry when they exit normally. The export is bounded by the context.
+func (lh *LogHelper) Flush(ctx context.Context) error {
+	err1 := lh.backend.Flush()
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to flush the log backend")
+	}
+
+	err2 := otelHelper.ForceFlush(ctx)
+
+	if err1 != nil && err2 != nil {
+		return errors.Wrap(err1, err2.Error())
+	} else if err1 != nil {
+		return err1
+	}
+	return err2
+}
+
+// Flush flushes the shared LogHelper instance (refer to LogHelper.Flush).
+func Flush(ctx context.Context) error {
+	return GetLogHelper().Flush(ctx)
+}
+
+// syncWriter syncs the writer if it supports it (e.g. files). Pipes and terminals, like stderr in containers, have
+// nothing to sync and reject it, so they are skipped.
+func syncWriter(writer io.Writer) error {
+	syncer, ok := writer.(interface{ Sync() error })
+	if !ok {
+		return nil
+	}
+	if file, ok := writer.(*os.File); ok {
+		if info, err := file.Stat(); err == nil && !info.Mode().IsRegular() {
+			return nil
+		}
+	}
+	return ignoreUnsupportedSync(syncer.Sync())
+}
+
+// ignoreUnsupportedSync returns nil if the error only reports that the output does not support syncing.
+func ignoreUnsupportedSync(err error) error {
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTSUP) {
+		return nil
+	}
+	return err
+}
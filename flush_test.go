@@ -0,0 +1,55 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// failingSyncer is a writer whose Sync fails.
+type failingSyncer struct{}
+
+func (failingSyncer) Write(p []byte) (int, error) { return len(p), nil }
+func (failingSyncer) Sync() error                 { return errors.New("Disk full") }
+
+func TestFlushSkipsPipes(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reader.Close(); _ = writer.Close() }()
+
+	lh := NewLogHelper(WithHooks(), WithOutput(writer))
+	if err := lh.Flush(context.Background()); err != nil {
+		t.Errorf("Flush of a pipe failed: %v", err)
+	}
+
+	router := &OutputRouter{Writers: map[Level]io.Writer{Info: writer}, Default: writer}
+	if err := NewLogHelper(WithHooks(), WithOutputRouter(router)).Flush(context.Background()); err != nil {
+		t.Errorf("Flush of a routed pipe failed: %v", err)
+	}
+}
+
+func TestFlushSyncsFiles(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+
+	lh := NewLogHelper(WithHooks(), WithOutput(file))
+	lh.Info(context.Background(), "Flushed")
+	if err := lh.Flush(context.Background()); err != nil {
+		t.Errorf("Flush of a file failed: %v", err)
+	}
+}
+
+func TestFlushReportsSyncErrors(t *testing.T) {
+	lh := NewLogHelper(WithHooks(), WithOutput(failingSyncer{}))
+	if err := lh.Flush(context.Background()); err == nil {
+		t.Error("Flush ignored a failed sync")
+	}
+}
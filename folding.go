@@ -0,0 +1,121 @@
+package FlowWatch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"sort"
+	"strings"
+)
+
+// FoldingFormatter is a logrus formatter that folds multi-line entries (e.g. stack traces) into a single line by
+// escaping the backslashes as \\ and the line breaks as \n. JSON entries are compacted instead (e.g. of a JSON
+// formatter with PrettyPrint), since JSON already escapes the line breaks in its strings. Use NewUnfoldReader to
+// reconstruct the readable entries from shipped logs.
+type FoldingFormatter struct {
+	Formatter logrus.Formatter
+}
+
+// foldReplacer escapes the backslashes and line breaks of the folded lines (reversed by unfoldReplacer).
+var foldReplacer = strings.NewReplacer(`\`, `\\`, "\r\n", `\n`, "\n", `\n`)
+
+// unfoldReplacer restores the backslashes and line breaks of the folded lines.
+var unfoldReplacer = strings.NewReplacer(`\\`, `\`, `\n`, "\n")
+
+// Format formats the entry with the wrapped formatter and escapes all line breaks except the terminating one.
+func (f *FoldingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	line, err := f.Formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	line = bytes.TrimRight(line, "\r\n")
+
+	if isJSONObject(line) {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, line); err == nil {
+			return append(compact.Bytes(), '\n'), nil
+		}
+	}
+
+	return append([]byte(foldReplacer.Replace(string(line))), '\n'), nil
+}
+
+// isJSONObject reports whether the line is a JSON object.
+func isJSONObject(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	return len(line) > 0 && line[0] == '{' && json.Valid(line)
+}
+
+// unfoldedKeys are the keys of a JSON entry which are printed in the header line instead of the field list.
+var unfoldedKeys = map[string]bool{"time": true, "level": true, "msg": true}
+
+// unfoldReader is an io.Reader that reconstructs readable, multi-line entries from folded log lines.
+type unfoldReader struct {
+	scanner *bufio.Scanner
+	buf     bytes.Buffer
+}
+
+// NewUnfoldReader returns a reader that reconstructs readable entries (e.g. stack traces) from folded log lines.
+// JSON entries are printed as a header line with time, level and message followed by the indented fields,
+// other lines are printed with their escaped line breaks restored.
+func NewUnfoldReader(r io.Reader) io.Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // Allow large entries, e.g. long stack traces
+
+	return &unfoldReader{scanner: scanner}
+}
+
+// Read reads the unfolded entries, unfolding the next line whenever the internal buffer is exhausted.
+func (r *unfoldReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return 0, err
+			}
+			return 0, io.EOF
+		}
+		unfoldLine(&r.buf, r.scanner.Text())
+	}
+
+	return r.buf.Read(p)
+}
+
+// unfoldLine writes the unfolded representation of a single log line to the buffer. JSON entries are not escaped by
+// the FoldingFormatter (refer to FoldingFormatter.Format).
+func unfoldLine(buf *bytes.Buffer, line string) {
+	var entry map[string]interface{}
+	if !isJSONObject([]byte(line)) || json.Unmarshal([]byte(line), &entry) != nil || entry == nil {
+		buf.WriteString(unfoldReplacer.Replace(line))
+		buf.WriteByte('\n')
+		return
+	}
+
+	// Print the header line and indent continuation lines of the message
+	fmt.Fprintf(buf, "%v [%v] %s\n", entry["time"], entry["level"], indent(fmt.Sprint(entry["msg"]), "    "))
+
+	// Print the remaining fields in a stable order, multi-line values start on a new line
+	keys := make([]string, 0, len(entry))
+	for key := range entry {
+		if !unfoldedKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := fmt.Sprint(entry[key])
+		if strings.Contains(value, "\n") {
+			fmt.Fprintf(buf, "    %s:\n        %s\n", key, indent(strings.TrimRight(value, "\n"), "        "))
+		} else {
+			fmt.Fprintf(buf, "    %s=%s\n", key, value)
+		}
+	}
+}
+
+// indent prefixes all lines except the first one with the given indentation.
+func indent(text, indentation string) string {
+	return strings.ReplaceAll(text, "\n", "\n"+indentation)
+}
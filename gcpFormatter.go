@@ -0,0 +1,82 @@
+package FlowWatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// gcpSeverities maps the logrus levels to the LogSeverity values of Google Cloud Logging.
+var gcpSeverities = map[logrus.Level]string{
+	logrus.TraceLevel: "DEBUG",
+	logrus.DebugLevel: "DEBUG",
+	logrus.InfoLevel:  "INFO",
+	logrus.WarnLevel:  "WARNING",
+	logrus.ErrorLevel: "ERROR",
+	logrus.FatalLevel: "CRITICAL",
+	logrus.PanicLevel: "ALERT",
+}
+
+// GCPFormatter is a logrus formatter writing JSON entries with the special fields of Google Cloud Logging, so that
+// the logging agent of GKE and Cloud Run parses the severity and source location and correlates the entries with
+// Cloud Trace. The structured fields are added unchanged to the jsonPayload.
+type GCPFormatter struct {
+	// ProjectID is the Google Cloud project of the traces; the trace field is only added if it is set
+	ProjectID string
+}
+
+// Format formats the entry as Google Cloud Logging JSON line.
+func (f *GCPFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(map[string]interface{}, len(entry.Data)+8)
+	for key, value := range entry.Data {
+		switch key {
+		case ErrorKey, StackKey, "file", "line", TraceIDKey, SpanIDKey:
+			continue // Mapped to the special fields below
+		case LoggerKey:
+			data["logging.googleapis.com/labels"] = map[string]interface{}{"logger": value}
+		default:
+			if err, ok := value.(error); ok {
+				value = err.Error() // Errors are not serialized by encoding/json
+			}
+			data[key] = value
+		}
+	}
+
+	data["time"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	data["severity"] = gcpSeverities[entry.Level]
+	data["message"] = entry.Message
+
+	if file, ok := entry.Data["file"]; ok {
+		data["logging.googleapis.com/sourceLocation"] = map[string]interface{}{
+			"file": file,
+			"line": fmt.Sprint(entry.Data["line"]), // The line is an int64 and thus encoded as string
+		}
+	}
+
+	if err, ok := entry.Data[ErrorKey].(error); ok {
+		data["error"] = err.Error()
+		if stack, ok := entry.Data[StackKey].(string); ok {
+			data["stack_trace"] = stack // Picked up by Error Reporting
+		}
+	}
+
+	if entry.Context != nil {
+		if spanContext := trace.SpanContextFromContext(entry.Context); spanContext.IsValid() {
+			if f.ProjectID != "" {
+				data["logging.googleapis.com/trace"] = fmt.Sprintf("projects/%s/traces/%s", f.ProjectID,
+					spanContext.TraceID())
+			}
+			data["logging.googleapis.com/spanId"] = spanContext.SpanID().String()
+			data["logging.googleapis.com/trace_sampled"] = spanContext.IsSampled()
+		}
+	}
+
+	line, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to marshal the GCP entry")
+	}
+	return append(line, '\n'), nil
+}
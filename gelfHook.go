@@ -0,0 +1,244 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultGELFChunkSize is the default maximum size of a GELF UDP datagram (fits into the MTU of most networks).
+const DefaultGELFChunkSize = 1420
+
+// gelfMaxChunks is the maximum number of chunks of a GELF message.
+const gelfMaxChunks = 128
+
+// gelfChunkHeaderSize is the size of the header of a GELF chunk (magic bytes, message id, sequence number and count).
+const gelfChunkHeaderSize = 12
+
+// GELFMessageTooLargeError is returned if a message does not fit into the maximum number of GELF chunks.
+var GELFMessageTooLargeError = errors.New("GELF message too large")
+
+// gelfInvalidFieldChars matches the characters which are not allowed in the names of GELF additional fields.
+var gelfInvalidFieldChars = regexp.MustCompile(`[^\w.\-]`)
+
+// GELFConfig configures the LogrusGELFHook.
+type GELFConfig struct {
+	Address     string // Address of the Graylog input, e.g. graylog:12201
+	Protocol    string // udp (default) or tcp
+	Host        string // Host reported in the messages (default: the hostname)
+	ChunkSize   int    // Maximum size of a UDP datagram (default: DefaultGELFChunkSize)
+	Compression bool   // Whether UDP messages are compressed with gzip (TCP messages are never compressed)
+	MaxBuffered int    // Messages buffered while Graylog is unreachable (default: DefaultBatchBufferedEvents)
+}
+
+// GELFConfigFromEnv returns the configuration from FLOWWATCH_GELF_ADDRESS and FLOWWATCH_GELF_PROTOCOL with
+// compression enabled. The address is empty if the GELF output is not configured.
+func GELFConfigFromEnv() GELFConfig {
+	return GELFConfig{
+		Address:     otelHelper.Getenv("FLOWWATCH_GELF_ADDRESS"),
+		Protocol:    otelHelper.Getenv("FLOWWATCH_GELF_PROTOCOL"),
+		Compression: true,
+	}
+}
+
+// LogrusGELFHook is a hook for logrus that ships the entries to Graylog using GELF over UDP (chunked and optionally
+// compressed) or TCP. The fields are added as GELF additional fields. The messages are sent from a background
+// goroutine, so that an unreachable Graylog input does not block the logging goroutines.
+type LogrusGELFHook struct {
+	config  GELFConfig
+	batcher *batcher
+	conn    sinkConn // Connection to the Graylog input, only used by the batcher
+}
+
+// NewGELFHook creates a hook shipping the entries to the Graylog input configured by the config.
+func NewGELFHook(config GELFConfig) *LogrusGELFHook {
+	if config.Protocol == "" {
+		config.Protocol = "udp"
+	}
+	if config.Host == "" {
+		config.Host, _ = os.Hostname()
+	}
+	if config.ChunkSize <= gelfChunkHeaderSize {
+		config.ChunkSize = DefaultGELFChunkSize
+	}
+
+	hook := &LogrusGELFHook{config: config}
+	hook.conn.dial = func() (net.Conn, error) {
+		conn, err := net.DialTimeout(config.Protocol, config.Address, 5*time.Second)
+		return conn, errors.Wrapf(err, "Failed to connect to the GELF input %s", config.Address)
+	}
+	hook.batcher = newBatcher(batchConfig{
+		maxEvents: 1, // Every message is sent on its own, so that a failed send does not repeat sent messages
+		interval:  sinkSendInterval,
+		retries:   -1, // The reconnects are backed off by the connection
+
+		maxBufferedEvents: config.MaxBuffered,
+	}, hook.send)
+
+	return hook
+}
+
+// Levels returns all log levels for which the LogrusGELFHook should be activated (all levels).
+func (hook *LogrusGELFHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire is called when the LogrusGELFHook is activated (when a log entry is made). The message is encoded and queued
+// for the background goroutine.
+func (hook *LogrusGELFHook) Fire(entry *logrus.Entry) error {
+	payload, err := json.Marshal(hook.message(entry))
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode the GELF message")
+	}
+
+	if hook.config.Protocol == "tcp" {
+		payload = append(payload, 0) // Null byte delimited (GELF over TCP does not support compression)
+	} else if hook.config.Compression {
+		if payload, err = gzipPayload(payload); err != nil {
+			return errors.Wrap(err, "Failed to compress the GELF message")
+		}
+	}
+
+	hook.batcher.add(batchEvent{time: entry.Time, level: levelFromLogrus(entry.Level), line: string(payload)})
+	return nil
+}
+
+// Flush sends the queued messages.
+func (hook *LogrusGELFHook) Flush(ctx context.Context) error {
+	return hook.batcher.Flush(ctx)
+}
+
+// Close sends the remaining messages, stops the background goroutine and closes the connection to the Graylog input.
+func (hook *LogrusGELFHook) Close(ctx context.Context) error {
+	err := hook.batcher.Close(ctx)
+	if closeErr := hook.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// send writes the message of the batch (refer to NewGELFHook).
+func (hook *LogrusGELFHook) send(_ context.Context, events []batchEvent) error {
+	for _, event := range events {
+		payload := []byte(event.line)
+
+		var err error
+		if hook.config.Protocol == "tcp" {
+			err = hook.conn.write(payload)
+		} else {
+			err = hook.writeUDP(payload)
+		}
+		if err != nil {
+			return errors.Wrap(err, "Failed to send the GELF message")
+		}
+	}
+	return nil
+}
+
+// message maps the entry to a GELF message. The fields are added as additional fields prefixed with an underscore.
+func (hook *LogrusGELFHook) message(entry *logrus.Entry) map[string]interface{} {
+	short, full, _ := strings.Cut(entry.Message, "\n")
+
+	message := map[string]interface{}{
+		"version":       "1.1",
+		"host":          hook.config.Host,
+		"short_message": short,
+		"timestamp":     float64(entry.Time.UnixNano()) / float64(time.Second),
+		"level":         syslogSeverity(levelFromLogrus(entry.Level)),
+	}
+	if full != "" {
+		message["full_message"] = entry.Message
+	}
+
+	for key, value := range entry.Data {
+		name := "_" + gelfInvalidFieldChars.ReplaceAllString(key, "_")
+		if name == "_id" { // Reserved by Graylog
+			name = "_id_"
+		}
+
+		switch v := value.(type) {
+		case error:
+			message[name] = v.Error()
+		case string, bool, int, int32, int64, uint, uint32, uint64, float32, float64:
+			message[name] = v
+		default:
+			message[name] = fmt.Sprint(v)
+		}
+	}
+
+	return message
+}
+
+// gzipPayload returns the payload compressed with gzip.
+func gzipPayload(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeUDP writes the (already compressed) payload in a single datagram or in several chunks.
+func (hook *LogrusGELFHook) writeUDP(payload []byte) error {
+	if len(payload) <= hook.config.ChunkSize {
+		return hook.conn.write(payload)
+	}
+
+	chunkSize := hook.config.ChunkSize - gelfChunkHeaderSize
+	count := (len(payload) + chunkSize - 1) / chunkSize
+	if count > gelfMaxChunks {
+		return errors.Wrapf(GELFMessageTooLargeError, "%d bytes exceed %d chunks", len(payload), gelfMaxChunks)
+	}
+
+	// Every chunk starts with the magic bytes, the message id, the sequence number and the number of chunks
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	for seq := 0; seq < count; seq++ {
+		end := min((seq+1)*chunkSize, len(payload))
+
+		chunk := make([]byte, 0, gelfChunkHeaderSize+end-seq*chunkSize)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(seq), byte(count))
+		chunk = append(chunk, payload[seq*chunkSize:end]...)
+
+		if err := hook.conn.write(chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syslogSeverity maps the level to the syslog severity (RFC 5424), which is also used by GELF.
+func syslogSeverity(level Level) int {
+	switch level {
+	case Trace, Debug:
+		return 7 // Debug
+	case Info:
+		return 6 // Informational
+	case Warn:
+		return 4 // Warning
+	case Error:
+		return 3 // Error
+	case Fatal:
+		return 2 // Critical
+	default:
+		return 1 // Alert
+	}
+}
@@ -0,0 +1,100 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"sync"
+	"time"
+)
+
+// GovernorConfig configures the load governor. Debug logs are suppressed while the export latency exceeds the
+// DebugThreshold and Info logs while it exceeds the InfoThreshold. The levels are restored once the latency drops
+// below half of the respective threshold. A threshold of zero disables the respective suppression.
+type GovernorConfig struct {
+	DebugThreshold time.Duration
+	InfoThreshold  time.Duration
+}
+
+// Suppression states of the load governor.
+const (
+	governorIdle = iota
+	governorDebugSuppressed
+	governorInfoSuppressed
+)
+
+// loadGovernor suppresses verbose log levels of a LogHelper while the telemetry pipeline is saturated.
+type loadGovernor struct {
+	mu         sync.Mutex
+	lh         *LogHelper
+	config     GovernorConfig
+	state      int
+	configured Level // Level configured before the suppression started
+	enforced   Level // Level set by the governor
+}
+
+// EnableLoadGovernor monitors the span export latency and automatically suppresses Debug (then Info) logs of the
+// shared LogHelper instance when the pipeline is saturated. Every state change is logged.
+func EnableLoadGovernor(config GovernorConfig) {
+	g := &loadGovernor{lh: GetLogHelper(), config: config}
+	otelHelper.OnExportLatency(g.observe)
+}
+
+// observe updates the suppression state according to the latest export latency.
+func (g *loadGovernor) observe(latency time.Duration, _ error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state := g.state
+	switch {
+	case exceeds(latency, g.config.InfoThreshold):
+		state = governorInfoSuppressed
+	case state == governorInfoSuppressed && !relieved(latency, g.config.InfoThreshold):
+		// Keep suppressing Info logs until the latency dropped below half of the threshold
+	case exceeds(latency, g.config.DebugThreshold) || (state != governorIdle && !relieved(latency, g.config.DebugThreshold)):
+		state = governorDebugSuppressed
+	default:
+		state = governorIdle
+	}
+
+	if state != g.state {
+		g.transition(state, latency)
+	}
+}
+
+// transition applies the new suppression state to the logger and logs the change.
+func (g *loadGovernor) transition(state int, latency time.Duration) {
+	ctx := context.Background()
+	current := g.lh.GetLevel()
+
+	if g.state == governorIdle {
+		g.configured = current
+	} else if current != g.enforced {
+		g.configured = current // The level has been changed in the meantime, which takes precedence when restoring
+	}
+	g.state = state
+
+	entry := g.lh.WithField(ctx, "export_latency", latency.String())
+	switch state {
+	case governorIdle:
+		g.lh.SetLevel(g.configured)
+		entry.Warn("Telemetry pipeline recovered, restoring the log level")
+		return
+	case governorDebugSuppressed:
+		g.enforced = mostSevere(g.configured, Info)
+		entry.Warn("Telemetry pipeline saturated, suppressing Debug logs")
+	case governorInfoSuppressed:
+		g.enforced = mostSevere(g.configured, Warn)
+		entry.Warn("Telemetry pipeline saturated, suppressing Debug and Info logs")
+	}
+	g.lh.SetLevel(g.enforced)
+}
+
+// exceeds reports whether the latency exceeds the (enabled) threshold.
+func exceeds(latency, threshold time.Duration) bool {
+	return threshold > 0 && latency >= threshold
+}
+
+// relieved reports whether the latency dropped below half of the threshold (or the threshold is disabled).
+func relieved(latency, threshold time.Duration) bool {
+	return threshold <= 0 || latency < threshold/2
+}
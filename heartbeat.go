@@ -0,0 +1,83 @@
+package FlowWatch
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultHeartbeatInterval is the default interval of the heartbeats.
+const DefaultHeartbeatInterval = 5 * time.Minute
+
+// processStart is the start time of the process, which is used to calculate the uptime.
+var processStart = time.Now()
+
+// HeartbeatConfig configures the heartbeats started with StartHeartbeat.
+type HeartbeatConfig struct {
+	Interval    time.Duration // Interval of the heartbeats (default: DefaultHeartbeatInterval)
+	DisableSpan bool          // Whether no heartbeat span is exported
+	DisableLog  bool          // Whether no heartbeat entry is logged
+}
+
+// StartHeartbeat periodically exports a minimal span and logs an entry with the uptime and basic runtime statistics,
+// so that dashboards can distinguish an idle service from a broken telemetry pipeline. The returned function stops
+// the heartbeats.
+func StartHeartbeat(config HeartbeatConfig) (stop func()) {
+	if config.Interval <= 0 {
+		config.Interval = DefaultHeartbeatInterval
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				heartbeat(config)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// heartbeat exports the heartbeat span and logs the heartbeat entry.
+func heartbeat(config HeartbeatConfig) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	uptime := int64(time.Since(processStart).Seconds())
+	goroutines := runtime.NumGoroutine()
+	heapBytes := int64(memStats.HeapAlloc)
+	gcCycles := int64(memStats.NumGC)
+
+	ctx := context.Background()
+	if !config.DisableSpan {
+		var span trace.Span
+		ctx, span = tracer.Start(ctx, "heartbeat", trace.WithAttributes(
+			attribute.Int64("uptime_s", uptime),
+			attribute.Int("goroutines", goroutines),
+			attribute.Int64("heap_bytes", heapBytes),
+			attribute.Int64("gc_cycles", gcCycles),
+		))
+		defer span.End()
+	}
+	if !config.DisableLog {
+		GetLogHelper().WithFields(ctx, Fields{
+			"uptime_s":   uptime,
+			"goroutines": goroutines,
+			"heap_bytes": heapBytes,
+			"gc_cycles":  gcCycles,
+		}).Info("Heartbeat")
+	}
+}
@@ -0,0 +1,141 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// journaldSocket is the socket of the native journald protocol.
+const journaldSocket = "/run/systemd/journal/socket"
+
+// LogrusJournaldHook is a hook for logrus that writes the entries to journald using the native protocol. The level is
+// mapped to PRIORITY (enabling journalctl -p err), the caller to CODE_FILE and CODE_LINE and the fields to upper case
+// journal fields (e.g. order_id to ORDER_ID).
+type LogrusJournaldHook struct {
+	identifier string
+
+	mu   sync.Mutex
+	conn *net.UnixConn // Connection to journald, nil until the first entry or after a failed write
+}
+
+// NewJournaldHook creates a hook writing the entries to journald with the identifier as SYSLOG_IDENTIFIER
+// (default: the name of the executable).
+func NewJournaldHook(identifier string) *LogrusJournaldHook {
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+	return &LogrusJournaldHook{identifier: identifier}
+}
+
+// JournaldAvailable reports whether the journald socket exists, e.g. if the service is managed by systemd.
+func JournaldAvailable() bool {
+	_, err := os.Stat(journaldSocket)
+	return err == nil
+}
+
+// Levels returns all log levels for which the LogrusJournaldHook should be activated (all levels).
+func (hook *LogrusJournaldHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire is called when the LogrusJournaldHook is activated (when a log entry is made).
+func (hook *LogrusJournaldHook) Fire(entry *logrus.Entry) error {
+	message := hook.message(entry)
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if hook.conn == nil {
+		conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+		if err != nil {
+			return errors.Wrap(err, "Failed to connect to journald")
+		}
+		hook.conn = conn
+	}
+
+	// Entries exceeding the datagram size would have to be passed as file descriptor, so they are dropped
+	if _, err := hook.conn.Write(message); err != nil {
+		_ = hook.conn.Close()
+		hook.conn = nil // Reconnect on the next entry
+		return errors.Wrap(err, "Failed to write the journal entry")
+	}
+
+	return nil
+}
+
+// Close closes the connection to journald.
+func (hook *LogrusJournaldHook) Close() error {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if hook.conn == nil {
+		return nil
+	}
+	err := hook.conn.Close()
+	hook.conn = nil
+
+	return err
+}
+
+// message serializes the entry in the native journald protocol.
+func (hook *LogrusJournaldHook) message(entry *logrus.Entry) []byte {
+	var buf bytes.Buffer
+	writeJournalField(&buf, "MESSAGE", entry.Message)
+	writeJournalField(&buf, "PRIORITY", fmt.Sprint(syslogSeverity(levelFromLogrus(entry.Level))))
+	writeJournalField(&buf, "SYSLOG_IDENTIFIER", hook.identifier)
+
+	for key, value := range entry.Data {
+		switch key {
+		case "file":
+			writeJournalField(&buf, "CODE_FILE", fmt.Sprint(value))
+		case "line":
+			writeJournalField(&buf, "CODE_LINE", fmt.Sprint(value))
+		default:
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			writeJournalField(&buf, journalFieldName(key), fmt.Sprint(value))
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// writeJournalField appends the field as KEY=value line or, if the value contains line breaks, in the binary format
+// (KEY, line break, little endian 64 bit length and value).
+func writeJournalField(buf *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(name + "=" + value + "\n")
+		return
+	}
+
+	buf.WriteString(name + "\n")
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value + "\n")
+}
+
+// journalFieldName converts the key into a valid journal field name (upper case letters, digits and underscores,
+// not starting with an underscore, which is reserved for trusted fields).
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, key)
+
+	name = strings.TrimLeft(name, "_")
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "F_" + name
+	}
+	return name
+}
@@ -0,0 +1,84 @@
+package FlowWatch
+
+import "context"
+
+// Lazy is a field value which is only evaluated if the entry is actually logged, e.g. for expensive serialization:
+//
+//	lh.WithField(ctx, "request", FlowWatch.Lazy(func() any { return dump(request) })).Debug("Request received")
+type Lazy func() any
+
+// resolveLazyFields returns the fields with all lazy values evaluated.
+func resolveLazyFields(fields Fields) Fields {
+	var resolved Fields
+	for key, value := range fields {
+		lazy, ok := value.(Lazy)
+		if !ok {
+			continue
+		}
+
+		if resolved == nil { // Copy the fields on the first lazy value, since they may be shared by several entries
+			resolved = make(Fields, len(fields))
+			for k, v := range fields {
+				resolved[k] = v
+			}
+		}
+		resolved[key] = lazy()
+	}
+
+	if resolved == nil {
+		return fields
+	}
+	return resolved
+}
+
+// Lazy variants of the log functions. The message function is only called if the level is enabled.
+
+// TraceFn logs the message returned by fn at the trace level.
+func (lh *LogHelper) TraceFn(ctx context.Context, fn func() string) {
+	lh.logFn(ctx, Trace, nil, fn)
+}
+
+// DebugFn logs the message returned by fn at the debug level.
+func (lh *LogHelper) DebugFn(ctx context.Context, fn func() string) {
+	lh.logFn(ctx, Debug, nil, fn)
+}
+
+// InfoFn logs the message returned by fn at the info level.
+func (lh *LogHelper) InfoFn(ctx context.Context, fn func() string) {
+	lh.logFn(ctx, Info, nil, fn)
+}
+
+// WarnFn logs the message returned by fn at the warning level.
+func (lh *LogHelper) WarnFn(ctx context.Context, fn func() string) {
+	lh.logFn(ctx, Warn, nil, fn)
+}
+
+// ErrorFn logs the message returned by fn at the error level.
+func (lh *LogHelper) ErrorFn(ctx context.Context, fn func() string) {
+	lh.logFn(ctx, Error, nil, fn)
+}
+
+// TraceFn logs the message returned by fn with the fields of the entry at the trace level.
+func (e *Entry) TraceFn(fn func() string) {
+	e.lh.logFn(e.ctx, Trace, e.fields, fn)
+}
+
+// DebugFn logs the message returned by fn with the fields of the entry at the debug level.
+func (e *Entry) DebugFn(fn func() string) {
+	e.lh.logFn(e.ctx, Debug, e.fields, fn)
+}
+
+// InfoFn logs the message returned by fn with the fields of the entry at the info level.
+func (e *Entry) InfoFn(fn func() string) {
+	e.lh.logFn(e.ctx, Info, e.fields, fn)
+}
+
+// WarnFn logs the message returned by fn with the fields of the entry at the warning level.
+func (e *Entry) WarnFn(fn func() string) {
+	e.lh.logFn(e.ctx, Warn, e.fields, fn)
+}
+
+// ErrorFn logs the message returned by fn with the fields of the entry at the error level.
+func (e *Entry) ErrorFn(fn func() string) {
+	e.lh.logFn(e.ctx, Error, e.fields, fn)
+}
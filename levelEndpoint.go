@@ -0,0 +1,99 @@
+package FlowWatch
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// levelPayload is the JSON shape of the level endpoint, which extends the shape of zap's atomic level endpoint
+// ({"level":"info"}) with the levels of the named loggers.
+type levelPayload struct {
+	Level   *string           `json:"level,omitempty"`
+	Logger  string            `json:"logger,omitempty"`
+	Loggers map[string]string `json:"loggers,omitempty"`
+}
+
+// levelEndpoint is the http.Handler returned by LevelHandler.
+type levelEndpoint struct {
+	lh *LogHelper
+}
+
+// LevelHandler returns an http.Handler for runtime log level changes of the shared LogHelper instance, so that
+// operators can temporarily raise the verbosity without a restart. The endpoint is compatible with zap's atomic
+// level endpoint:
+//
+//	GET returns the current levels: {"level":"info","loggers":{"storage":"warn"}}
+//	PUT changes a level: {"level":"debug"} or {"level":"debug","logger":"storage"} (or the form values level and logger)
+func LevelHandler() http.Handler {
+	return GetLogHelper().LevelHandler()
+}
+
+// LevelHandler returns an http.Handler for runtime log level changes of the LogHelper (refer to the LevelHandler
+// function).
+func (lh *LogHelper) LevelHandler() http.Handler {
+	return levelEndpoint{lh: lh.root}
+}
+
+// ServeHTTP returns or changes the log levels.
+func (e levelEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		e.writeLevels(w)
+	case http.MethodPut:
+		e.putLevel(w, r)
+	default:
+		writeLevelError(w, http.StatusMethodNotAllowed, "Only GET and PUT are supported.")
+	}
+}
+
+// putLevel changes the level of the root logger or of a named logger.
+func (e levelEndpoint) putLevel(w http.ResponseWriter, r *http.Request) {
+	var payload levelPayload
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		name := r.FormValue("level")
+		payload.Level = &name
+		payload.Logger = r.FormValue("logger")
+	} else if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeLevelError(w, http.StatusBadRequest, "Request body must be well-formed JSON: "+err.Error())
+		return
+	}
+
+	if payload.Level == nil {
+		writeLevelError(w, http.StatusBadRequest, "Must specify a logging level.")
+		return
+	}
+	level, err := ParseLevel(*payload.Level)
+	if err != nil {
+		writeLevelError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	target := e.lh
+	if payload.Logger != "" {
+		target = e.lh.Named(payload.Logger)
+	}
+	target.SetLevel(level)
+	target.WithField(r.Context(), "new_level", level.String()).Warn("Log level changed")
+
+	e.writeLevels(w)
+}
+
+// writeLevels writes the current levels of the root logger and all named loggers.
+func (e levelEndpoint) writeLevels(w http.ResponseWriter) {
+	level := strings.ToLower(e.lh.GetLevel().String())
+	payload := levelPayload{Level: &level, Loggers: make(map[string]string)}
+	for name, namedLevel := range e.lh.namedLevels() {
+		payload.Loggers[name] = strings.ToLower(namedLevel.String())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+// writeLevelError writes an error in the shape of zap's atomic level endpoint.
+func writeLevelError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
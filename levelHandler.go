@@ -1,8 +1,17 @@
 package FlowWatch
 
-import "github.com/sirupsen/logrus"
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// UnknownLevelError is returned if a level name cannot be parsed.
+var UnknownLevelError = errors.New("Unknown log level")
 
-// Level is an enumeration for the log levels to abstract it from the logging library.
+// Level is an enumeration for the log levels to abstract it from the logging library. The values are stable
+// (Panic and Trace were appended), so they are not ordered by severity: compare levels with Level.AtLeast or
+// Level.Severity instead.
 type Level uint32
 
 const (
@@ -11,11 +20,47 @@ const (
 	Warn
 	Error
 	Fatal
+	Panic Level = 5
+	Trace Level = 6 // Less severe than Debug
 )
 
+// allLevels contains the levels ordered from the least to the most severe.
+var allLevels = []Level{Trace, Debug, Info, Warn, Error, Fatal, Panic}
+
+// Severity returns the rank of the level, which increases with the severity (Trace ranks below Debug).
+func (l Level) Severity() int {
+	if l == Trace {
+		return -1
+	}
+	return int(l)
+}
+
+// AtLeast reports whether the level is at least as severe as the threshold, e.g. Error.AtLeast(Warn) is true.
+func (l Level) AtLeast(threshold Level) bool {
+	return l.Severity() >= threshold.Severity()
+}
+
+// mostSevere returns the more severe of the two levels.
+func mostSevere(a, b Level) Level {
+	if a.AtLeast(b) {
+		return a
+	}
+	return b
+}
+
+// leastSevere returns the less severe of the two levels.
+func leastSevere(a, b Level) Level {
+	if a.AtLeast(b) {
+		return b
+	}
+	return a
+}
+
 // String returns the string representation of the log level.
 func (l Level) String() string {
 	switch l {
+	case Trace:
+		return "Trace"
 	case Debug:
 		return "Debug"
 	case Info:
@@ -26,13 +71,31 @@ func (l Level) String() string {
 		return "Error"
 	case Fatal:
 		return "Fatal"
+	case Panic:
+		return "Panic"
 	}
 	return "Unknown"
 }
 
+// ParseLevel returns the level for the (case-insensitive) level name, e.g. "debug" or "Warn".
+func ParseLevel(name string) (Level, error) {
+	for _, level := range allLevels {
+		if strings.EqualFold(name, level.String()) {
+			return level, nil
+		}
+	}
+	if strings.EqualFold(name, "warning") {
+		return Warn, nil
+	}
+
+	return Debug, errors.Wrapf(UnknownLevelError, "Failed to parse %q", name)
+}
+
 // getLogrusLevel translates the Level enumeration to the logrus log level.
 func (l Level) getLogrusLevel() logrus.Level {
 	switch l {
+	case Trace:
+		return logrus.TraceLevel
 	case Debug:
 		return logrus.DebugLevel
 	case Info:
@@ -43,12 +106,55 @@ func (l Level) getLogrusLevel() logrus.Level {
 		return logrus.ErrorLevel
 	case Fatal:
 		return logrus.FatalLevel
+	case Panic:
+		return logrus.PanicLevel
 	default:
 		return logrus.DebugLevel
 	}
 }
 
-// SetLogLevel updates the log level of the logger library.
+// levelFromLogrus translates the logrus log level to the Level enumeration.
+func levelFromLogrus(level logrus.Level) Level {
+	switch level {
+	case logrus.TraceLevel:
+		return Trace
+	case logrus.DebugLevel:
+		return Debug
+	case logrus.InfoLevel:
+		return Info
+	case logrus.WarnLevel:
+		return Warn
+	case logrus.ErrorLevel:
+		return Error
+	case logrus.FatalLevel:
+		return Fatal
+	default:
+		return Panic
+	}
+}
+
+// GetLevel returns the current log level of the LogHelper.
+func (lh *LogHelper) GetLevel() Level {
+	if levels := lh.packageLevels.Load(); levels != nil {
+		return levels.getDefaultLevel()
+	}
+	return lh.backend.GetLevel()
+}
+
+// SetLevel updates the log level of the LogHelper (clamped to the active policy, refer to ApplyPolicy).
+func (lh *LogHelper) SetLevel(level Level) {
+	level = lh.clampPolicyLevel(level)
+
+	// With per-package levels, the level applies to the packages without a matching rule
+	if levels := lh.packageLevels.Load(); levels != nil {
+		levels.setDefaultLevel(level)
+		lh.backend.SetLevel(levels.minLevel())
+		return
+	}
+	lh.backend.SetLevel(level)
+}
+
+// SetLogLevel updates the log level of the shared LogHelper instance (refer to SetNamedLogLevel for named loggers).
 func SetLogLevel(level Level) {
-	GetLogHelper().Logger.SetLevel(level.getLogrusLevel())
+	GetLogHelper().SetLevel(level)
 }
@@ -0,0 +1,89 @@
+package FlowWatch
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"log/slog"
+	"testing"
+)
+
+func TestLevelSeverityOrder(t *testing.T) {
+	for i := 1; i < len(allLevels); i++ {
+		less, more := allLevels[i-1], allLevels[i]
+		if less.Severity() >= more.Severity() {
+			t.Errorf("%s ranks at least as severe as %s", less, more)
+		}
+		if less.AtLeast(more) || !more.AtLeast(less) {
+			t.Errorf("AtLeast does not order %s below %s", less, more)
+		}
+	}
+	if mostSevere(Trace, Debug) != Debug || leastSevere(Panic, Fatal) != Fatal {
+		t.Error("mostSevere and leastSevere do not follow the severity")
+	}
+}
+
+func TestLevelLogrusMapping(t *testing.T) {
+	for _, level := range allLevels {
+		if got := levelFromLogrus(level.getLogrusLevel()); got != level {
+			t.Errorf("%s maps back to %s", level, got)
+		}
+	}
+	for i := 1; i < len(allLevels); i++ {
+		// logrus orders its levels from the most to the least severe
+		if allLevels[i-1].getLogrusLevel() <= allLevels[i].getLogrusLevel() {
+			t.Errorf("logrus level of %s is not below the one of %s", allLevels[i-1], allLevels[i])
+		}
+	}
+}
+
+func TestLevelZapMapping(t *testing.T) {
+	want := map[Level]zapcore.Level{
+		Trace: zapLevelTrace, Debug: zapcore.DebugLevel, Info: zapcore.InfoLevel, Warn: zapcore.WarnLevel,
+		Error: zapcore.ErrorLevel, Fatal: zapcore.FatalLevel, Panic: zapcore.PanicLevel,
+	}
+	for level, zapLevelWant := range want {
+		if got := zapLevel(level); got != zapLevelWant {
+			t.Errorf("%s maps to %s, want %s", level, got, zapLevelWant)
+		}
+	}
+
+	// zap orders its panic level below its fatal level, so the backend must not compare zap levels
+	backend := NewZapBackend(zap.NewNop())
+	backend.SetLevel(Fatal)
+	if !backend.IsLevelEnabled(Panic) || backend.IsLevelEnabled(Error) {
+		t.Error("zap backend does not order the levels by severity")
+	}
+}
+
+func TestLevelSlogMapping(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  Level
+	}{
+		{slog.LevelDebug - 4, Trace},
+		{slog.LevelDebug, Debug},
+		{slog.LevelInfo, Info},
+		{slog.LevelWarn, Warn},
+		{slog.LevelError, Error},
+		{slog.LevelError + 8, Error}, // Libraries must not terminate the program
+	}
+	for _, test := range tests {
+		if got := levelFromSlog(test.level); got != test.want {
+			t.Errorf("%s maps to %s, want %s", test.level, got, test.want)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range allLevels {
+		if got, err := ParseLevel(level.String()); err != nil || got != level {
+			t.Errorf("ParseLevel(%q) = %s, %v", level.String(), got, err)
+		}
+	}
+	if got, err := ParseLevel("WARNING"); err != nil || got != Warn {
+		t.Errorf("ParseLevel(\"WARNING\") = %s, %v", got, err)
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel accepted an unknown level")
+	}
+}
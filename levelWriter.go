@@ -0,0 +1,81 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// levelWriter is an io.Writer which logs every written line. The level is taken from a level prefix of the line
+// (e.g. "[ERROR]" or "warning:") and defaults to the level of the writer.
+type levelWriter struct {
+	mu    sync.Mutex
+	lh    *LogHelper
+	ctx   context.Context
+	level Level
+	buf   bytes.Buffer
+}
+
+// WriterLevel returns an io.Writer for libraries which only accept a writer (e.g. http.Server.ErrorLog or database
+// drivers). Every written line is logged at the given level, unless the line starts with a level prefix like
+// "[ERROR]" or "warning:". Fatal and panic prefixes are logged at the error level to never terminate the program.
+func (lh *LogHelper) WriterLevel(ctx context.Context, level Level) io.Writer {
+	return &levelWriter{lh: lh, ctx: ctx, level: level}
+}
+
+// Write buffers the data and logs all complete lines.
+func (w *levelWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			w.buf.WriteString(line) // Keep the incomplete line until the rest is written
+			break
+		}
+		w.logLine(strings.TrimRight(line, "\r\n"))
+	}
+
+	return len(p), nil
+}
+
+// logLine logs a single line at the level of its prefix or the default level of the writer.
+func (w *levelWriter) logLine(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+
+	level, msg := parseLevelPrefix(line, w.level)
+	w.lh.log(w.ctx, level, nil, msg)
+}
+
+// levelPrefixes maps the (lower case) level prefixes of third-party output to the levels.
+var levelPrefixes = map[string]Level{
+	"trace":    Trace,
+	"debug":    Debug,
+	"info":     Info,
+	"warn":     Warn,
+	"warning":  Warn,
+	"error":    Error,
+	"err":      Error,
+	"fatal":    Error,
+	"panic":    Error,
+	"critical": Error,
+}
+
+// parseLevelPrefix returns the level of the level prefix of the line (e.g. "[ERROR] msg", "warning: msg" or
+// "INFO msg") and the line without the prefix. The default level is returned if the line has no level prefix.
+func parseLevelPrefix(line string, defaultLevel Level) (Level, string) {
+	trimmed := strings.TrimSpace(line)
+	token, rest, _ := strings.Cut(trimmed, " ")
+	token = strings.Trim(token, "[]:")
+
+	if level, ok := levelPrefixes[strings.ToLower(token)]; ok {
+		return level, strings.TrimSpace(rest)
+	}
+	return defaultLevel, trimmed
+}
@@ -1,31 +1,152 @@
 package FlowWatch
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 // Abstraction for log functions to enable simpler switching between logging libraries.
 // Context is required to add the event to the span (if possible). Refer to the LogrusOtelHook for more information.
 
+// Trace logs a message at the trace level (very verbose output, e.g. protocol tracing).
+func (lh *LogHelper) Trace(ctx context.Context, args ...interface{}) {
+	lh.log(ctx, Trace, nil, args...)
+}
+
 // Debug logs a message at the debug level.
 func (lh *LogHelper) Debug(ctx context.Context, args ...interface{}) {
-	lh.Logger.WithContext(ctx).Debug(args...)
+	lh.log(ctx, Debug, nil, args...)
 }
 
 // Info logs a message at the info level.
 func (lh *LogHelper) Info(ctx context.Context, args ...interface{}) {
-	lh.Logger.WithContext(ctx).Info(args...)
+	lh.log(ctx, Info, nil, args...)
 }
 
 // Warn logs a message at the warning level.
 func (lh *LogHelper) Warn(ctx context.Context, args ...interface{}) {
-	lh.Logger.WithContext(ctx).Warn(args...)
+	lh.log(ctx, Warn, nil, args...)
 }
 
 // Error logs a message at the error level.
 func (lh *LogHelper) Error(ctx context.Context, args ...interface{}) {
-	lh.Logger.WithContext(ctx).Error(args...)
+	lh.log(ctx, Error, nil, args...)
 }
 
-// Fatal logs a message at the fatal level.
+// Fatal logs a message at the fatal level and terminates the program afterward.
 func (lh *LogHelper) Fatal(ctx context.Context, args ...interface{}) {
-	lh.Logger.WithContext(ctx).Fatal(args...)
+	lh.log(ctx, Fatal, nil, args...)
+}
+
+// Panic logs a message at the panic level and panics afterward.
+func (lh *LogHelper) Panic(ctx context.Context, args ...interface{}) {
+	lh.log(ctx, Panic, nil, args...)
+}
+
+// Formatted variants of the log functions. The message is only formatted if the level is enabled.
+
+// Tracef logs a formatted message at the trace level.
+func (lh *LogHelper) Tracef(ctx context.Context, format string, args ...interface{}) {
+	lh.logf(ctx, Trace, nil, format, args...)
+}
+
+// Debugf logs a formatted message at the debug level.
+func (lh *LogHelper) Debugf(ctx context.Context, format string, args ...interface{}) {
+	lh.logf(ctx, Debug, nil, format, args...)
+}
+
+// Infof logs a formatted message at the info level.
+func (lh *LogHelper) Infof(ctx context.Context, format string, args ...interface{}) {
+	lh.logf(ctx, Info, nil, format, args...)
+}
+
+// Warnf logs a formatted message at the warning level.
+func (lh *LogHelper) Warnf(ctx context.Context, format string, args ...interface{}) {
+	lh.logf(ctx, Warn, nil, format, args...)
+}
+
+// Errorf logs a formatted message at the error level.
+func (lh *LogHelper) Errorf(ctx context.Context, format string, args ...interface{}) {
+	lh.logf(ctx, Error, nil, format, args...)
+}
+
+// Fatalf logs a formatted message at the fatal level and terminates the program afterward.
+func (lh *LogHelper) Fatalf(ctx context.Context, format string, args ...interface{}) {
+	lh.logf(ctx, Fatal, nil, format, args...)
+}
+
+// Panicf logs a formatted message at the panic level and panics afterward.
+func (lh *LogHelper) Panicf(ctx context.Context, format string, args ...interface{}) {
+	lh.logf(ctx, Panic, nil, format, args...)
+}
+
+// log formats the message and passes the entry to the backend if the level is enabled.
+func (lh *LogHelper) log(ctx context.Context, level Level, fields Fields, args ...interface{}) {
+	if !lh.isEnabled(level) {
+		return
+	}
+	rate, sampled := lh.sample(level)
+	if !sampled {
+		return
+	}
+
+	lh.write(ctx, level, fields, rate, fmt.Sprint(args...))
+}
+
+// logf formats the message with the format and passes the entry to the backend if the level is enabled.
+func (lh *LogHelper) logf(ctx context.Context, level Level, fields Fields, format string, args ...interface{}) {
+	if !lh.isEnabled(level) {
+		return
+	}
+	rate, sampled := lh.sample(level)
+	if !sampled {
+		return
+	}
+
+	lh.write(ctx, level, fields, rate, fmt.Sprintf(format, args...))
+}
+
+// logFn evaluates the message function and passes the entry to the backend if the level is enabled.
+func (lh *LogHelper) logFn(ctx context.Context, level Level, fields Fields, fn func() string) {
+	if !lh.isEnabled(level) {
+		return
+	}
+	rate, sampled := lh.sample(level)
+	if !sampled {
+		return
+	}
+
+	lh.write(ctx, level, fields, rate, fn())
+}
+
+// write resolves the lazy fields, masks the sensitive values and passes the entry to the backend, unless it exceeds
+// the rate limit.
+func (lh *LogHelper) write(ctx context.Context, level Level, fields Fields, rate float64, msg string) {
+	fields = resolveLazyFields(fields)
+	fields = lh.maskStructFields(fields)
+	fields, msg = lh.redact(fields, msg)
+	if lh.isAllowed(level, fields, msg) {
+		countEntry(level)
+		lh.backend.Log(level, ctx, lh.withName(withSampleRate(fields, rate)), msg)
+	}
+
+	if level == Fatal {
+		_ = lh.backend.Flush() // A failed flush cannot be logged anymore
+		lh.exitFunc(fatalExitCode(fields))
+	}
+}
+
+// withName adds the name of the logger to the fields (if it is a named logger).
+func (lh *LogHelper) withName(fields Fields) Fields {
+	if lh.name == "" {
+		return fields
+	}
+
+	named := make(Fields, len(fields)+1)
+	for key, value := range fields {
+		named[key] = value
+	}
+	named[LoggerKey] = lh.name
+
+	return named
 }
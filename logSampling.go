@@ -0,0 +1,45 @@
+package FlowWatch
+
+import "math/rand/v2"
+
+// SampleRateKey is the key of the field containing the sample rate of a sampled entry, which enables the
+// extrapolation of the actual number of entries.
+const SampleRateKey = "sample_rate"
+
+// WithSampling logs only the given fraction (0 to 1) of the entries at the level, e.g. WithSampling(Debug, 0.01), so
+// that high-volume logging can be left enabled in production at low cost. The decision is made before formatting and
+// hooks, and the rate is added to the sampled entries as "sample_rate".
+func WithSampling(level Level, rate float64) Option {
+	return func(o *options) {
+		if o.sampling == nil {
+			o.sampling = make(map[Level]float64)
+		}
+		o.sampling[level] = rate
+	}
+}
+
+// sample decides whether an entry at the level is logged and returns the sample rate of the level (1 if the level
+// is not sampled).
+func (lh *LogHelper) sample(level Level) (float64, bool) {
+	rate, ok := lh.root.sampling[level]
+	if !ok || rate >= 1 {
+		return 1, true
+	}
+
+	return rate, rand.Float64() < rate
+}
+
+// withSampleRate adds the sample rate to the fields if the entry has been sampled.
+func withSampleRate(fields Fields, rate float64) Fields {
+	if rate >= 1 {
+		return fields
+	}
+
+	sampled := make(Fields, len(fields)+1)
+	for key, value := range fields {
+		sampled[key] = value
+	}
+	sampled[SampleRateKey] = rate
+
+	return sampled
+}
@@ -0,0 +1,72 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LogfmtFormatter is a logrus formatter writing logfmt lines (time=... level=info msg="..." key=value) for ingestion
+// pipelines preferring key/value pairs over JSON (e.g. Loki or Heroku). It is selected with FLOWWATCH_FORMAT=logfmt.
+type LogfmtFormatter struct {
+	TimestampFormat string // Default: RFC 3339
+}
+
+// Format formats the entry as a single logfmt line with the fields sorted by key.
+func (f *LogfmtFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = time.RFC3339
+	}
+
+	var buf bytes.Buffer
+	writeLogfmtPair(&buf, "time", entry.Time.Format(timestampFormat))
+	writeLogfmtPair(&buf, "level", entry.Level.String())
+	writeLogfmtPair(&buf, "msg", entry.Message)
+
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := entry.Data[key]
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		writeLogfmtPair(&buf, key, fmt.Sprint(value))
+	}
+
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// writeLogfmtPair appends the key/value pair and quotes the value if necessary.
+func writeLogfmtPair(buf *bytes.Buffer, key, value string) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(key)
+	buf.WriteByte('=')
+
+	if needsLogfmtQuoting(value) {
+		buf.WriteString(strconv.Quote(value))
+	} else {
+		buf.WriteString(value)
+	}
+}
+
+// needsLogfmtQuoting reports whether the value is empty or contains spaces, quotes, equal signs or control characters.
+func needsLogfmtQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	return strings.IndexFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == unicode.ReplacementChar || !unicode.IsPrint(r)
+	}) >= 0
+}
@@ -1,8 +1,13 @@
 package FlowWatch
 
 import (
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
 	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,26 +17,191 @@ var (
 )
 
 // LogHelper is an abstraction for the Logger instance to enable simpler switching between logging libraries.
+// All entries are delegated to the Backend.
 type LogHelper struct {
+	// Logger is the logrus logger of the LogrusBackend, or nil for other backends.
+	//
+	// Deprecated: Use Backend instead, e.g. Backend().(*FlowWatch.LogrusBackend).Logger.
 	Logger *logrus.Logger
+
+	backend Backend
+
+	name  string        // Name of the logger, empty for the root logger
+	root  *LogHelper    // Root logger from which the named loggers are derived
+	named *namedLoggers // Registry of the named loggers, shared by the root logger and all named loggers
+
+	packageLevels atomic.Pointer[packageLevels] // Per-package levels, nil if not configured (refer to SetLevelSpec)
+	rateLimiter   atomic.Pointer[rateLimiter]   // Limit of identical entries, nil if not configured (refer to SetRateLimit)
+	redactor      atomic.Pointer[redactor]      // Masking of sensitive values, nil if not configured (refer to SetRedaction)
+	sampling      map[Level]float64             // Sample rates per level (refer to WithSampling)
+	exitFunc      func(code int)                // Terminates the program after fatal entries (refer to WithExitFunc)
+}
+
+// options holds the configuration of a LogHelper created by NewLogHelper.
+type options struct {
+	backend     Backend
+	level       Level
+	formatter   logrus.Formatter
+	hooks       []logrus.Hook
+	output      io.Writer
+	sampling    map[Level]float64
+	exitFunc    func(code int)
+	router      *OutputRouter
+	fieldFilter *FieldFilter
+}
+
+// Option configures a LogHelper created by NewLogHelper.
+type Option func(*options)
+
+// WithBackend sets the logging library behind the LogHelper (default: LogrusBackend). The logrus specific options
+// (formatter, hooks and output) are ignored if a backend is set.
+func WithBackend(backend Backend) Option {
+	return func(o *options) {
+		o.backend = backend
+	}
+}
+
+// WithLevel sets the log level (default: Info).
+func WithLevel(level Level) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
+
+// WithFormatter sets the formatter of the log entries (default: JSON with RFC 3339 timestamps, or the
+// ConsoleFormatter on a terminal if ENV is set to dev).
+func WithFormatter(formatter logrus.Formatter) Option {
+	return func(o *options) {
+		o.formatter = formatter
+	}
+}
+
+// WithHooks replaces the default hooks (see DefaultHooks) with the given hooks.
+func WithHooks(hooks ...logrus.Hook) Option {
+	return func(o *options) {
+		o.hooks = hooks
+	}
+}
+
+// WithOutput sets the writer to which the log entries are written (default: stderr).
+func WithOutput(output io.Writer) Option {
+	return func(o *options) {
+		o.output = output
+	}
+}
+
+// WithExitFunc sets the function called after fatal entries (default: os.Exit), e.g. to keep tests running or to
+// map the failure to a custom exit code. The OpenTelemetry connection has already been shut down when it is called.
+func WithExitFunc(exit func(code int)) Option {
+	return func(o *options) {
+		if exit == nil {
+			exit = os.Exit
+		}
+		o.exitFunc = exit
+	}
+}
+
+// DefaultHooks returns the hooks which are added to a LogHelper unless they are replaced using WithHooks.
+func DefaultHooks() []logrus.Hook {
+	return []logrus.Hook{
+		LogrusContextHook{},      // Add the file and line number to the log entry
+		LogrusTraceContextHook{}, // Add the trace and span ID to the log entry
+		LogrusBlobHook{},         // Replace large binary fields with their digest
+		LogrusSizeGuardHook{},    // Truncate oversize entries before they are exported
+		LogrusOtelHook{},         // Enable logging to OpenTelemetry
+		LogrusOtelShutdownHook{}, // Ensure that the connection is shut down properly
+	}
+}
+
+// NewLogHelper creates an isolated LogHelper instance configured by the given options. Use GetLogHelper to retrieve
+// the shared instance instead.
+func NewLogHelper(opts ...Option) *LogHelper {
+	o := &options{
+		level:    Info, // Set the default log level to info for production environments
+		hooks:    DefaultHooks(),
+		output:   os.Stderr,
+		exitFunc: os.Exit,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	backend := o.backend
+	if backend == nil {
+		if o.formatter == nil {
+			output := o.output
+			if o.router != nil {
+				output = o.router.writer(Info) // Detect the terminal on the writer of the regular entries
+			}
+			o.formatter = defaultFormatter(output)
+		}
+		if o.fieldFilter != nil {
+			o.formatter = filteredFormatter{formatter: o.formatter, filter: *o.fieldFilter}
+		}
+
+		logrusLogger := logrus.New()
+		logrusLogger.SetFormatter(o.formatter)
+		logrusLogger.SetOutput(o.output)
+		for _, hook := range o.hooks {
+			logrusLogger.AddHook(hook)
+		}
+		logrusBackend := NewLogrusBackend(logrusLogger)
+		if o.router != nil {
+			logrusBackend.routeOutput(o.router)
+		}
+		backend = logrusBackend
+	}
+	backend.SetLevel(o.level)
+
+	lh := &LogHelper{
+		Logger:   logrusLogger(backend),
+		backend:  backend,
+		named:    &namedLoggers{loggers: make(map[string]*LogHelper)},
+		sampling: o.sampling,
+		exitFunc: o.exitFunc,
+	}
+	lh.root = lh
+
+	return lh
+}
+
+// Backend returns the logging library behind the LogHelper.
+func (lh *LogHelper) Backend() Backend {
+	return lh.backend
 }
 
 // initLogHelper initializes the LogHelper instance.
 func initLogHelper() {
-	// Create a new logrus logger with a JSON formatter
-	logrusLogger := logrus.New()
-	logrusLogger.SetLevel(logrus.InfoLevel) // Set the default log level to info for production environments
-	logrusLogger.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat: time.RFC3339,
-	})
+	logHelper = NewLogHelper()
+	logHelper.applyLevelSpecFromEnv(otelHelper.Getenv("FLOWWATCH_LEVELS"))
+
+	// Add the sinks configured by the environment variables
+	if config := GELFConfigFromEnv(); config.Address != "" {
+		gelf := NewGELFHook(config)
+		logHelper.addHook(gelf)
+		otelHelper.RegisterShutdown("GELF sink", time.Second, gelf.Close)
+	}
+	logHelper.addSinksFromEnv(otelHelper.Getenv("FLOWWATCH_SINKS"))
 
-	logrusLogger.AddHook(LogrusContextHook{})      // Add the LogrusContextHook to add the file and line number to the log entry
-	logrusLogger.AddHook(LogrusOtelHook{})         // Add the LogrusOtelHook to enable logging to OpenTelemetry
-	logrusLogger.AddHook(LogrusOtelShutdownHook{}) // Add the LogrusOtelShutdownHook to ensure that the connection is shut down properly
+	// Copy the baggage members configured by the environment variables into the fields
+	if keys := otelHelper.Getenv("FLOWWATCH_BAGGAGE_FIELDS"); keys != "" {
+		logHelper.addHook(NewBaggageHook(strings.Split(keys, ",")...))
+	}
+}
+
+// addHook adds the hook to the logrus logger of the LogHelper (other backends do not support hooks).
+func (lh *LogHelper) addHook(hook logrus.Hook) {
+	if backend, ok := lh.backend.(*LogrusBackend); ok {
+		backend.AddHook(hook)
+	}
+}
 
-	logHelper = &LogHelper{
-		Logger: logrusLogger,
+// logrusLogger returns the logrus logger of the backend, or nil if it is not a LogrusBackend.
+func logrusLogger(backend Backend) *logrus.Logger {
+	if backend, ok := backend.(*LogrusBackend); ok {
+		return backend.Logger
 	}
+	return nil
 }
 
 // GetLogHelper returns the LogHelper instance or creates a new one if it does not exist according to the singleton pattern.
@@ -0,0 +1,147 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"sync"
+)
+
+// LogrusBackend is the Backend implementation based on logrus. The logger is exposed for logrus specific settings
+// (e.g. formatters). Hooks should be added with AddHook, so that they also apply to the loggers derived from it.
+type LogrusBackend struct {
+	Logger *logrus.Logger
+
+	routes map[logrus.Level]*logrus.Logger // Loggers writing to the writer of their level, nil without OutputRouter
+	hooks  *hookRegistry                   // Loggers sharing the hooks, shared with the children
+}
+
+// NewLogrusBackend creates a backend for the given logrus logger.
+func NewLogrusBackend(logger *logrus.Logger) *LogrusBackend {
+	return &LogrusBackend{Logger: logger, hooks: &hookRegistry{loggers: []*logrus.Logger{logger}}}
+}
+
+// AddHook adds the hook to the logger and all loggers derived from it (children and routed outputs).
+func (b *LogrusBackend) AddHook(hook logrus.Hook) {
+	b.hooks.add(hook)
+}
+
+// hookRegistry adds hooks to a logger and the loggers derived from it. Every logger has its own copy of the hooks map,
+// since logrus reads the hooks under the mutex of the logger firing them, which would not guard a shared map against
+// hooks added concurrently.
+type hookRegistry struct {
+	mu      sync.Mutex
+	loggers []*logrus.Logger
+}
+
+// add adds the hook to all loggers.
+func (r *hookRegistry) add(hook logrus.Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, logger := range r.loggers {
+		logger.AddHook(hook)
+	}
+}
+
+// register copies the hooks of the source logger to the new logger, which then receives the hooks added later.
+func (r *hookRegistry) register(logger, source *logrus.Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logger.Hooks = make(logrus.LevelHooks, len(source.Hooks))
+	for level, hooks := range source.Hooks {
+		logger.Hooks[level] = append([]logrus.Hook(nil), hooks...)
+	}
+	r.loggers = append(r.loggers, logger)
+}
+
+// Log writes the entry if the level is enabled.
+func (b *LogrusBackend) Log(level Level, ctx context.Context, fields Fields, msg string) {
+	logrusLevel := level.getLogrusLevel()
+	if !b.Logger.IsLevelEnabled(logrusLevel) {
+		return
+	}
+
+	logger := b.Logger
+	if routed, ok := b.routes[logrusLevel]; ok {
+		logger = routed
+	}
+	logger.WithContext(ctx).WithFields(logrus.Fields(fields)).Log(logrusLevel, msg)
+}
+
+// routeOutput writes the entries to the writer of their level. Every level gets a logger with the hooks and the
+// formatter of this logger, so that the entries are formatted once after all hooks (including hooks added later with
+// AddHook) and only the output differs.
+func (b *LogrusBackend) routeOutput(router *OutputRouter) {
+	root := b.Logger
+
+	b.routes = make(map[logrus.Level]*logrus.Logger, len(logrus.AllLevels))
+	for _, level := range logrus.AllLevels {
+		routed := &logrus.Logger{
+			Out:       router.writer(levelFromLogrus(level)),
+			Formatter: rootFormatter{root: root},
+			Level:     logrus.TraceLevel, // The level is checked by Log
+		}
+		b.hooks.register(routed, root)
+		b.routes[level] = routed
+	}
+}
+
+// IsLevelEnabled reports whether entries at the level are written.
+func (b *LogrusBackend) IsLevelEnabled(level Level) bool {
+	return b.Logger.IsLevelEnabled(level.getLogrusLevel())
+}
+
+// GetLevel returns the current log level.
+func (b *LogrusBackend) GetLevel() Level {
+	return levelFromLogrus(b.Logger.GetLevel())
+}
+
+// SetLevel updates the log level.
+func (b *LogrusBackend) SetLevel(level Level) {
+	b.Logger.SetLevel(level.getLogrusLevel())
+}
+
+// Child returns a backend with its own logrus logger, which forwards the output and formatting to this logger, so
+// that later changes of the configuration (including hooks added with AddHook) also apply to the child.
+func (b *LogrusBackend) Child() Backend {
+	root := b.Logger
+	child := &logrus.Logger{
+		Out:       rootWriter{root: root},
+		Formatter: rootFormatter{root: root},
+		Level:     root.GetLevel(),
+	}
+	b.hooks.register(child, root)
+
+	return &LogrusBackend{Logger: child, routes: b.routes, hooks: b.hooks}
+}
+
+// Flush syncs the output if it supports it (e.g. files, refer to syncWriter).
+func (b *LogrusBackend) Flush() error {
+	return syncWriter(b.Logger.Out)
+}
+
+// rootWriter forwards the output of a child logger to the output of the root logger.
+type rootWriter struct {
+	root *logrus.Logger
+}
+
+// Write writes the formatted entry to the current output of the root logger.
+func (w rootWriter) Write(p []byte) (int, error) {
+	return w.root.Out.Write(p)
+}
+
+// Sync syncs the output of the root logger if it supports it.
+func (w rootWriter) Sync() error {
+	return syncWriter(w.root.Out)
+}
+
+// rootFormatter forwards the formatting of a child logger to the formatter of the root logger.
+type rootFormatter struct {
+	root *logrus.Logger
+}
+
+// Format formats the entry with the current formatter of the root logger.
+func (f rootFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return f.root.Formatter.Format(entry)
+}
@@ -0,0 +1,62 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// countingHook counts the entries it has been fired for.
+type countingHook struct {
+	fired atomic.Int64
+}
+
+func (h *countingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *countingHook) Fire(*logrus.Entry) error {
+	h.fired.Add(1)
+	return nil
+}
+
+func TestLogrusBackendHooksReachDerivedLoggers(t *testing.T) {
+	router := &OutputRouter{Default: io.Discard}
+	lh := NewLogHelper(WithOutputRouter(router), WithHooks())
+	named := lh.Named("storage")
+
+	hook := &countingHook{}
+	lh.addHook(hook) // Added after the child and the routed loggers have been created
+
+	lh.Info(context.Background(), "root")
+	named.Warn(context.Background(), "child")
+
+	if fired := hook.fired.Load(); fired != 2 {
+		t.Errorf("hook fired %d times, want 2", fired)
+	}
+	if named.Logger == nil || named.Logger == lh.Logger {
+		t.Error("the named logger does not expose its own logrus logger")
+	}
+}
+
+func TestLogrusBackendAddHookConcurrently(t *testing.T) {
+	lh := NewLogHelper(WithOutputRouter(&OutputRouter{Default: io.Discard}), WithHooks())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			lh.addHook(&countingHook{})
+		}()
+		go func() {
+			defer wg.Done()
+			lh.Named("storage").Info(context.Background(), "entry")
+			lh.Info(context.Background(), "entry")
+		}()
+	}
+	wg.Wait()
+}
@@ -2,19 +2,21 @@ package FlowWatch
 
 import (
 	"context"
-	"fmt"
 	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
-	"runtime"
 	"time"
 )
 
 // LogrusContextHook is a hook for logrus that adds the file and line number to the log entry.
 type LogrusContextHook struct{}
 
+// LogrusTraceContextHook is a hook for logrus that adds the trace and span ID of the context to the log entry.
+type LogrusTraceContextHook struct{}
+
 // LogrusOtelHook is a hook for logrus that enables logging to OpenTelemetry.
 type LogrusOtelHook struct{}
 
@@ -22,9 +24,10 @@ type LogrusOtelHook struct{}
 type LogrusOtelShutdownHook struct{}
 
 // Levels returns all log levels for which the LogrusContextHook should be activated (warning level and higher,
-// because runtime.Caller is expensive and debug, because it should be disabled in production).
+// because runtime.Caller is expensive and trace and debug, because they should be disabled in production).
 func (hook LogrusContextHook) Levels() []logrus.Level {
 	return []logrus.Level{
+		logrus.TraceLevel,
 		logrus.DebugLevel,
 		logrus.WarnLevel,
 		logrus.ErrorLevel,
@@ -35,23 +38,61 @@ func (hook LogrusContextHook) Levels() []logrus.Level {
 
 // Fire is called when the LogrusContextHook is activated (when a log entry is made).
 func (hook LogrusContextHook) Fire(entry *logrus.Entry) error {
-	// Retrieve the call stack
-	_, file, line, ok := runtime.Caller(7) // The number of function calls to skip to get to the caller
+	addCaller(entry.Context, hookFields(entry))
+	return nil
+}
+
+// addCaller adds the file and line number of the caller to the fields.
+func addCaller(ctx context.Context, data *entryFields) {
+	// Keep the caller information if it has already been provided (e.g. by the slog handler)
+	if _, ok := data.data["file"]; ok {
+		return
+	}
+
+	// Retrieve the first frame outside FlowWatch and logrus (refer to SetCallerSkip)
+	_, frame, ok := callerFrame()
 
 	// Add the file and line number to the log entry
 	if !ok {
 		err := errors.New("unable to retrieve the caller information and thus the file and line number")
-		GetLogHelper().Debug(entry.Context, err)
-
-		return nil // The hook should not return an error to ensure that other hooks are also executed
+		GetLogHelper().Debug(ctx, err) // The hook should not return an error to ensure that other hooks are also executed
+		return
 	}
 
-	entry.Data["file"] = file
-	entry.Data["line"] = line
+	data.set("file", frame.File)
+	data.set("line", frame.Line)
+}
 
+// Levels returns all log levels for which the LogrusTraceContextHook should be activated (all levels, so that Info
+// entries, which never become span events, can be correlated with the trace as well).
+func (hook LogrusTraceContextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire is called when the LogrusTraceContextHook is activated (when a log entry is made).
+func (hook LogrusTraceContextHook) Fire(entry *logrus.Entry) error {
+	addTraceContext(entry.Context, hookFields(entry))
 	return nil
 }
 
+// addTraceContext adds the trace and span ID of the context to the fields.
+func addTraceContext(ctx context.Context, data *entryFields) {
+	if ctx == nil {
+		return
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		data.set(TraceIDKey, spanContext.TraceID().String())
+		data.set(SpanIDKey, spanContext.SpanID().String())
+	}
+}
+
+// reservedAttributeKeys are the attribute keys set by the LogrusOtelHook itself, which fields must not overwrite, and
+// the trace context fields, which the span and log record carry anyway.
+var reservedAttributeKeys = map[string]bool{
+	"msg": true, "level": true, "file": true, "line": true, "time": true, TraceIDKey: true, SpanIDKey: true,
+}
+
 // Levels returns all log levels for which the LogrusOtelHook should be activated (warning level and higher).
 func (hook LogrusOtelHook) Levels() []logrus.Level {
 	return []logrus.Level{
@@ -64,39 +105,98 @@ func (hook LogrusOtelHook) Levels() []logrus.Level {
 
 // Fire is called when the LogrusOtelHook is activated (when a log entry is made).
 func (hook LogrusOtelHook) Fire(entry *logrus.Entry) error {
+	markUnexportedEvent(entry.Context, hookFields(entry))
+	exportLogEvent(entry.Context, levelFromLogrus(entry.Level), entry.Message, entry.Data, entry.Time)
+	return nil
+}
+
+// exportLogEvent adds the log entry as an event to the span from the context and records an attached error on it, or
+// emits it as log record if there is no recording span. It is shared by all backends to ensure the same behavior.
+func exportLogEvent(ctx context.Context, level Level, msg string, data map[string]interface{}, t time.Time) {
+	raiseSeverityPriority(ctx, level)
+
+	// Export the entries without a recording span as log records instead of span events
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		emitLogRecord(ctx, level, msg, data, t)
+		return
+	}
 
-	// Helper function to check the type and set a default value
+	// Helper function to keep the type and set a default value
 	getAttributeValue := func(key string, defaultValue string) attribute.KeyValue {
-		if value, ok := entry.Data[key]; ok {
-			switch v := value.(type) {
-			case string:
-				return attribute.String(key, v)
-			case int:
-				return attribute.String(key, fmt.Sprintf("%d", v)) // Convert int to string
-			}
+		if value, ok := data[key]; ok {
+			return otelHelper.Attr.Any(key, value)
 		}
 		return attribute.String(key, defaultValue)
 	}
 
 	// Create attributes
-	messageValue := attribute.String("msg", entry.Message)
-	levelValue := attribute.String("level", entry.Level.String())
+	messageValue := attribute.String("msg", msg)
+	levelValue := attribute.String("level", level.getLogrusLevel().String())
 	fileValue := getAttributeValue("file", "unknown")
 	lineValue := getAttributeValue("line", "unknown")
-	timeValue := attribute.String("time", entry.Time.Format(time.RFC3339))
+	timeValue := attribute.String("time", t.Format(time.RFC3339))
 
-	addEvent(entry.Context, messageValue, levelValue, fileValue, lineValue, timeValue)
+	attributes := []attribute.KeyValue{messageValue, levelValue, fileValue, lineValue, timeValue}
 
-	return nil
+	// Add the structured fields of the entry with their types (the file and line fields have already been added above)
+	for key, value := range data {
+		if !reservedAttributeKeys[key] {
+			attributes = append(attributes, otelHelper.Attr.Any(key, value))
+		}
+	}
+
+	name := getEventNamer()(level, msg, data)
+	err, hasError := data[ErrorKey].(error)
+
+	// Errors logged by otelHelper.RecordError have already been recorded on the span
+	if hasError && isRecordedError(ctx) {
+		return
+	}
+
+	// Record the error attached with WithError on the span, which adds an "exception" event itself
+	if hasError && name == ExceptionEventName {
+		recordError(ctx, err, data[StackKey], level.AtLeast(Error), attributes...)
+		return
+	}
+
+	addEvent(ctx, name, attributes...)
+	if hasError {
+		recordError(ctx, err, data[StackKey], level.AtLeast(Error))
+	}
 }
 
 // addEvent adds an event to the trace span.
-func addEvent(ctx context.Context, args ...attribute.KeyValue) {
+func addEvent(ctx context.Context, name string, args ...attribute.KeyValue) {
 	span := trace.SpanFromContext(ctx)
 	if span != nil {
 		// Add the event to the span
-		span.AddEvent("log", trace.WithAttributes(args...))
-		// TODO: Use otel log exporter to export logs even if there is no surrounding span
+		span.AddEvent(name, trace.WithAttributes(args...))
+	}
+}
+
+// recordError records the error on the span from the context and marks the span as failed if requested.
+// The attributes are added to the exception event.
+func recordError(ctx context.Context, err error, stack interface{}, setStatus bool, attributes ...attribute.KeyValue) {
+	span := trace.SpanFromContext(ctx)
+
+	if stack, ok := stack.(string); ok {
+		attributes = append(attributes, attribute.String("exception.stacktrace", stack))
+	}
+
+	// Add the root cause and the wrapped causes, which the exception type of the wrapper does not reveal
+	object := NewErrorObject(err)
+	attributes = append(attributes, attribute.String("exception.root_type", object.Type))
+	if len(object.Causes) > 0 {
+		causes := make([]string, 0, len(object.Causes))
+		for _, cause := range object.Causes {
+			causes = append(causes, cause.Type+": "+cause.Message)
+		}
+		attributes = append(attributes, attribute.StringSlice("exception.causes", causes))
+	}
+	span.RecordError(err, trace.WithAttributes(attributes...))
+
+	if setStatus {
+		span.SetStatus(codes.Error, err.Error())
 	}
 }
 
@@ -111,6 +211,6 @@ func (hook LogrusOtelShutdownHook) Levels() []logrus.Level {
 
 // Fire is called when the LogrusOtelShutdownHook is activated (when a fatal log entry is made).
 func (hook LogrusOtelShutdownHook) Fire(entry *logrus.Entry) error {
-	otelHelper.Shutdown() // Shutdown the OpenTelemetry connection
+	shutdownOnTermination(entry.Context, levelFromLogrus(entry.Level))
 	return nil
 }
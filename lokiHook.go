@@ -0,0 +1,185 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits of a Loki push request.
+const (
+	lokiMaxEvents     = 1000
+	lokiMaxBatchBytes = 1024 * 1024
+)
+
+// LokiPushError is returned if Loki rejected a push request.
+var LokiPushError = errors.New("Loki push failed")
+
+// LokiConfig configures the LogrusLokiHook. The label values support the placeholders of the CloudWatchConfig
+// (e.g. {service} or {pod}).
+type LokiConfig struct {
+	URL           string            // Base URL of Loki, e.g. http://loki:3100
+	Labels        map[string]string // Stream labels (default: service={service})
+	LevelLabel    bool              // Whether the level is added as "level" label
+	TenantID      string            // Tenant of multi-tenant deployments (X-Scope-OrgID header)
+	Username      string            // Username of the basic authentication
+	Password      string            // Password of the basic authentication
+	FlushInterval time.Duration     // Interval after which the batch is pushed (default: DefaultBatchInterval)
+	MaxRetries    int               // Retries of failed requests (default: DefaultBatchRetries, < 0 disables them)
+	MaxBuffered   int               // Entries buffered while the service is unreachable (default: DefaultBatchBufferedEvents)
+	Client        *http.Client      // HTTP client (default: a client with a timeout of 10 seconds)
+}
+
+// lokiPushRequest is the body of the push API.
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+// lokiStream is a stream of a push request with its labels and the values (timestamp in nanoseconds and line).
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// LogrusLokiHook is a hook for logrus that batches the formatted entries and pushes them to Grafana Loki via the HTTP
+// push API, so that small deployments can skip the OpenTelemetry collector. Failed requests are retried with
+// exponential backoff.
+type LogrusLokiHook struct {
+	config  LokiConfig
+	labels  map[string]string
+	batcher *batcher
+}
+
+// NewLokiHook creates a hook pushing the entries to the Loki instance of the config.
+func NewLokiHook(config LokiConfig) *LogrusLokiHook {
+	if config.Labels == nil {
+		config.Labels = map[string]string{"service": "{service}"}
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = DefaultBatchRetries
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	labels := make(map[string]string, len(config.Labels))
+	for name, value := range config.Labels {
+		labels[name] = expandSinkTemplate(value)
+	}
+
+	hook := &LogrusLokiHook{config: config, labels: labels}
+	hook.batcher = newBatcher(batchConfig{
+		maxEvents: lokiMaxEvents,
+		maxBytes:  lokiMaxBatchBytes,
+		interval:  config.FlushInterval,
+		retries:   config.MaxRetries,
+
+		maxBufferedEvents: config.MaxBuffered,
+	}, hook.send)
+
+	return hook
+}
+
+// Levels returns all log levels for which the LogrusLokiHook should be activated (all levels).
+func (hook *LogrusLokiHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire is called when the LogrusLokiHook is activated (when a log entry is made).
+func (hook *LogrusLokiHook) Fire(entry *logrus.Entry) error {
+	line, err := entry.Logger.Formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	hook.batcher.add(batchEvent{
+		time:  entry.Time,
+		level: levelFromLogrus(entry.Level),
+		line:  strings.TrimSuffix(string(line), "\n"),
+	})
+	return nil
+}
+
+// Flush pushes the collected entries.
+func (hook *LogrusLokiHook) Flush(ctx context.Context) error {
+	return hook.batcher.Flush(ctx)
+}
+
+// Close pushes the remaining entries and stops the background goroutine.
+func (hook *LogrusLokiHook) Close(ctx context.Context) error {
+	return hook.batcher.Close(ctx)
+}
+
+// send pushes the batch, grouped into one stream per level if the level label is enabled.
+func (hook *LogrusLokiHook) send(ctx context.Context, events []batchEvent) error {
+	streams := make(map[Level]*lokiStream)
+	var request lokiPushRequest
+	for _, event := range events {
+		key := event.level
+		if !hook.config.LevelLabel {
+			key = Info // All entries share a single stream
+		}
+
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: hook.streamLabels(event.level)}
+			streams[key] = stream
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(event.time.UnixNano(), 10), event.line})
+	}
+	for _, stream := range streams {
+		request.Streams = append(request.Streams, *stream)
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode the Loki push request")
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimSuffix(hook.config.URL, "/")+"/loki/api/v1/push", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "Failed to create the Loki push request")
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	if hook.config.TenantID != "" {
+		httpRequest.Header.Set("X-Scope-OrgID", hook.config.TenantID)
+	}
+	if hook.config.Username != "" {
+		httpRequest.SetBasicAuth(hook.config.Username, hook.config.Password)
+	}
+
+	response, err := hook.config.Client.Do(httpRequest)
+	if err != nil {
+		return errors.Wrap(err, "Failed to push to Loki")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return errors.Wrapf(LokiPushError, "Status %d: %s", response.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// streamLabels returns the labels of the stream of the level.
+func (hook *LogrusLokiHook) streamLabels(level Level) map[string]string {
+	if !hook.config.LevelLabel {
+		return hook.labels
+	}
+
+	labels := make(map[string]string, len(hook.labels)+1)
+	for name, value := range hook.labels {
+		labels[name] = value
+	}
+	labels["level"] = strings.ToLower(level.String())
+
+	return labels
+}
@@ -0,0 +1,68 @@
+package FlowWatch
+
+import "sync"
+
+// LoggerKey is the key of the field containing the name of a named logger.
+const LoggerKey = "logger"
+
+// namedLoggers is the registry of the named loggers derived from a root LogHelper.
+type namedLoggers struct {
+	mu      sync.Mutex
+	loggers map[string]*LogHelper
+}
+
+// Named returns the scoped logger with the given name, which stamps the "logger" field on every entry and has its own
+// log level (initially the level of the logger it is derived from). Calling Named on a named logger nests the names
+// separated by a dot. Output, formatter and hooks are shared with the root logger.
+func (lh *LogHelper) Named(name string) *LogHelper {
+	if lh.name != "" {
+		name = lh.name + "." + name
+	}
+
+	lh.named.mu.Lock()
+	defer lh.named.mu.Unlock()
+
+	if named, ok := lh.named.loggers[name]; ok {
+		return named
+	}
+
+	backend := lh.root.backend.Child()
+	backend.SetLevel(lh.backend.GetLevel())
+
+	named := &LogHelper{
+		Logger:   logrusLogger(backend),
+		backend:  backend,
+		name:     name,
+		root:     lh.root,
+		named:    lh.named,
+		exitFunc: lh.root.exitFunc,
+	}
+	lh.named.loggers[name] = named
+
+	return named
+}
+
+// Name returns the name of the logger or an empty string for the root logger.
+func (lh *LogHelper) Name() string {
+	return lh.name
+}
+
+// SetNamedLogLevel updates the log level of the named logger independently of the global log level, e.g.
+// SetNamedLogLevel("storage", Warn). The named logger is created if it does not exist yet. It is separate from
+// SetLogLevel, which keeps its signature for the global log level so that existing callers do not break.
+func SetNamedLogLevel(name string, level Level) {
+	GetLogHelper().Named(name).SetLevel(level)
+}
+
+// namedLevels returns the current levels of all named loggers.
+func (lh *LogHelper) namedLevels() map[string]Level {
+	lh.named.mu.Lock()
+	defer lh.named.mu.Unlock()
+
+	levels := make(map[string]Level, len(lh.named.loggers))
+	for name, named := range lh.named.loggers {
+		levels[name] = named.GetLevel()
+	}
+
+	return levels
+}
@@ -0,0 +1,127 @@
+package otelHelper
+
+import (
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"math"
+	"time"
+)
+
+// Attr creates correctly typed span attributes, e.g. otelHelper.Attr.Int("retries", n) or otelHelper.Attr.Err(err).
+var Attr AttrBuilder
+
+// AttrBuilder creates typed span attributes (refer to Attr).
+type AttrBuilder struct{}
+
+// String returns a string attribute.
+func (AttrBuilder) String(key, value string) attribute.KeyValue {
+	return attribute.String(key, value)
+}
+
+// Int returns an integer attribute.
+func (AttrBuilder) Int(key string, value int) attribute.KeyValue {
+	return attribute.Int(key, value)
+}
+
+// Int64 returns an integer attribute.
+func (AttrBuilder) Int64(key string, value int64) attribute.KeyValue {
+	return attribute.Int64(key, value)
+}
+
+// Float64 returns a floating point attribute.
+func (AttrBuilder) Float64(key string, value float64) attribute.KeyValue {
+	return attribute.Float64(key, value)
+}
+
+// Bool returns a boolean attribute.
+func (AttrBuilder) Bool(key string, value bool) attribute.KeyValue {
+	return attribute.Bool(key, value)
+}
+
+// Strings returns a string slice attribute.
+func (AttrBuilder) Strings(key string, values []string) attribute.KeyValue {
+	return attribute.StringSlice(key, values)
+}
+
+// Duration returns the duration in seconds as floating point attribute, the unit of the semantic conventions.
+func (AttrBuilder) Duration(key string, value time.Duration) attribute.KeyValue {
+	return attribute.Float64(key, value.Seconds())
+}
+
+// Time returns the time as RFC 3339 string attribute.
+func (AttrBuilder) Time(key string, value time.Time) attribute.KeyValue {
+	return attribute.String(key, value.Format(time.RFC3339Nano))
+}
+
+// Err returns the message of the error as "error" attribute (empty if err is nil).
+func (AttrBuilder) Err(err error) attribute.KeyValue {
+	if err == nil {
+		return attribute.String("error", "")
+	}
+	return attribute.String("error", err.Error())
+}
+
+// Any returns an attribute of the type matching the value. Values without a matching attribute type (e.g. structs)
+// are formatted as string.
+func (builder AttrBuilder) Any(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case nil:
+		return attribute.String(key, "<nil>")
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int8:
+		return attribute.Int64(key, int64(v))
+	case int16:
+		return attribute.Int64(key, int64(v))
+	case int32:
+		return attribute.Int64(key, int64(v))
+	case int64:
+		return attribute.Int64(key, v)
+	case uint8:
+		return attribute.Int64(key, int64(v))
+	case uint16:
+		return attribute.Int64(key, int64(v))
+	case uint32:
+		return attribute.Int64(key, int64(v))
+	case uint:
+		return builder.uint64(key, uint64(v))
+	case uint64:
+		return builder.uint64(key, v)
+	case float32:
+		return attribute.Float64(key, float64(v))
+	case float64:
+		return attribute.Float64(key, v)
+	case time.Duration:
+		return builder.Duration(key, v)
+	case time.Time:
+		return builder.Time(key, v)
+	case error:
+		return attribute.String(key, v.Error())
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	case []string:
+		return attribute.StringSlice(key, v)
+	case []bool:
+		return attribute.BoolSlice(key, v)
+	case []int:
+		return attribute.IntSlice(key, v)
+	case []int64:
+		return attribute.Int64Slice(key, v)
+	case []float64:
+		return attribute.Float64Slice(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
+
+// uint64 returns an integer attribute or, if the value exceeds the range of int64, a string attribute.
+func (AttrBuilder) uint64(key string, value uint64) attribute.KeyValue {
+	if value > math.MaxInt64 {
+		return attribute.String(key, fmt.Sprint(value))
+	}
+	return attribute.Int64(key, int64(value))
+}
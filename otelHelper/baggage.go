@@ -0,0 +1,28 @@
+package otelHelper
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// SetBaggage returns a copy of the context whose baggage contains the member (e.g. the tenant, user or request ID),
+// which is propagated to all downstream services. Existing members with the same key are replaced.
+func SetBaggage(ctx context.Context, key, value string) (context.Context, error) {
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx, errors.Wrapf(err, "Failed to create the baggage member %q", key)
+	}
+
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, errors.Wrapf(err, "Failed to add the baggage member %q", key)
+	}
+
+	return baggage.ContextWithBaggage(ctx, bag), nil
+}
+
+// GetBaggage returns the value of the baggage member of the context, or an empty string if it is not set.
+func GetBaggage(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
@@ -0,0 +1,103 @@
+package otelHelper
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// cgroupRoot is the mount point of the cgroup file system.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// unlimitedMemory is the threshold above which cgroup v1 memory limits are considered unlimited.
+const unlimitedMemory = 1 << 62
+
+// cgroupLimits are the resource limits of the cgroup of the process (zero if unlimited or unknown).
+type cgroupLimits struct {
+	cpu    float64 // Number of CPUs (quota / period)
+	memory int64   // Memory limit in bytes
+}
+
+// readCgroupLimits reads the CPU and memory limits of the cgroup (v2 or v1) of the process.
+func readCgroupLimits() cgroupLimits {
+	var limits cgroupLimits
+
+	// cgroup v2: cpu.max contains "<quota> <period>" or "max <period>", memory.max contains the limit or "max"
+	dir := filepath.Join(cgroupRoot, cgroupV2Path())
+	if fields := strings.Fields(readCgroupFile(filepath.Join(dir, "cpu.max"))); len(fields) == 2 {
+		limits.cpu = cpuLimit(fields[0], fields[1])
+		limits.memory, _ = strconv.ParseInt(readCgroupFile(filepath.Join(dir, "memory.max")), 10, 64)
+		return limits
+	}
+
+	// cgroup v1: the quota is -1 if unlimited and the memory limit is a huge number if unlimited
+	limits.cpu = cpuLimit(
+		readCgroupFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_quota_us")),
+		readCgroupFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_period_us")),
+	)
+	memory, err := strconv.ParseInt(readCgroupFile(filepath.Join(cgroupRoot, "memory", "memory.limit_in_bytes")), 10, 64)
+	if err == nil && memory < unlimitedMemory {
+		limits.memory = memory
+	}
+
+	return limits
+}
+
+// cgroupV2Path returns the path of the cgroup v2 of the process relative to the cgroup root.
+func cgroupV2Path() string {
+	for _, line := range strings.Split(readCgroupFile("/proc/self/cgroup"), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return path
+		}
+	}
+	return "/"
+}
+
+// readCgroupFile returns the trimmed content of the file or an empty string if it cannot be read.
+func readCgroupFile(name string) string {
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// cpuLimit returns the number of CPUs of the quota and period or zero if the quota is unlimited or invalid.
+func cpuLimit(quota, period string) float64 {
+	q, err1 := strconv.ParseFloat(quota, 64)
+	p, err2 := strconv.ParseFloat(period, 64)
+	if err1 != nil || err2 != nil || q <= 0 || p <= 0 {
+		return 0
+	}
+	return q / p
+}
+
+// attributes returns the limits and GOMAXPROCS as resource attributes.
+func (limits cgroupLimits) attributes() []attribute.KeyValue {
+	attributes := []attribute.KeyValue{attribute.Int("go.gomaxprocs", runtime.GOMAXPROCS(0))}
+	if limits.cpu > 0 {
+		attributes = append(attributes, attribute.Float64("container.cpu.limit", limits.cpu))
+	}
+	if limits.memory > 0 {
+		attributes = append(attributes, attribute.Int64("container.memory.limit", limits.memory))
+	}
+	return attributes
+}
+
+// logCgroupDiagnostics logs the limits and warns if GOMAXPROCS does not match the CPU limit, which causes throttling
+// (too many threads) or idle CPUs (too few threads).
+func logCgroupDiagnostics(limits cgroupLimits) {
+	maxProcs := runtime.GOMAXPROCS(0)
+	log.Printf("Runtime limits: GOMAXPROCS=%d, cgroup CPU limit=%.2f, cgroup memory limit=%d bytes (0 = unlimited)",
+		maxProcs, limits.cpu, limits.memory)
+
+	if limits.cpu > 0 && maxProcs != int(math.Max(1, math.Ceil(limits.cpu))) {
+		log.Printf("GOMAXPROCS=%d does not match the cgroup CPU limit of %.2f, which may cause CPU throttling or "+
+			"idle CPUs. Set GOMAXPROCS accordingly", maxProcs, limits.cpu)
+	}
+}
@@ -0,0 +1,45 @@
+package otelHelper
+
+import (
+	"github.com/pkg/errors"
+	_ "google.golang.org/grpc/encoding/gzip" // Register the gzip compressor of gRPC
+	"strings"
+	"sync/atomic"
+)
+
+// Compressions of the OTLP exporters.
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+)
+
+// InvalidCompressionError is returned if an unsupported compression is configured.
+var InvalidCompressionError = errors.New("Invalid compression")
+
+var compression atomic.Pointer[string]
+
+// SetCompression sets the compression of the OTLP exporters (gzip or none), which takes precedence over
+// OTEL_EXPORTER_OTLP_COMPRESSION. Gzip cuts the egress of high-volume services at the cost of some CPU. It has to be
+// called before SetupOtelHelper.
+func SetCompression(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name != CompressionNone && name != CompressionGzip {
+		return errors.Wrapf(InvalidCompressionError, "Compression %q", name)
+	}
+
+	compression.Store(&name)
+	return nil
+}
+
+// getCompression returns the configured compression, read from OTEL_EXPORTER_OTLP_COMPRESSION if it has not been set
+// (default: none).
+func getCompression() string {
+	if configured := compression.Load(); configured != nil {
+		return *configured
+	}
+
+	if strings.ToLower(Getenv("OTEL_EXPORTER_OTLP_COMPRESSION")) == CompressionGzip {
+		return CompressionGzip
+	}
+	return CompressionNone
+}
@@ -0,0 +1,143 @@
+package otelHelper
+
+import (
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTypoDistance is the maximum edit distance at which an unused variable is considered a typo of a read one.
+const maxTypoDistance = 2
+
+// ConfigRead describes the first read of a configuration key.
+type ConfigRead struct {
+	Time   time.Time // Time of the first read
+	Caller string    // Function which read the key first
+	Set    bool      // Whether the variable was set
+}
+
+var (
+	configMu    sync.Mutex
+	configReads = make(map[string]ConfigRead)
+)
+
+// Getenv returns the value of the environment variable like os.Getenv and records which key was read when and by
+// whom, so that unused (e.g. misspelled) variables can be reported with ReportUnusedEnv.
+func Getenv(key string) string {
+	value, set := os.LookupEnv(key)
+
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	if _, ok := configReads[key]; !ok {
+		caller := "unknown"
+		if pc, _, _, ok := runtime.Caller(1); ok {
+			caller = runtime.FuncForPC(pc).Name()
+		}
+		configReads[key] = ConfigRead{Time: time.Now(), Caller: caller, Set: set}
+	}
+
+	return value
+}
+
+// ConfigReads returns the configuration keys read with Getenv so far.
+func ConfigReads() map[string]ConfigRead {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	reads := make(map[string]ConfigRead, len(configReads))
+	for key, read := range configReads {
+		reads[key] = read
+	}
+	return reads
+}
+
+// ReportUnusedEnv logs and returns the environment variables with one of the prefixes (e.g. "OTEL_" or "FLOWWATCH_")
+// which have not been read with Getenv. Variables similar to a read key are reported as probable typos. It should be
+// called once the configuration has been read, e.g. at the end of the startup.
+func ReportUnusedEnv(prefixes ...string) []string {
+	unused := unusedEnv(prefixes...)
+
+	keys := make([]string, 0, len(unused))
+	for key, suggestion := range unused {
+		keys = append(keys, key)
+		if suggestion != "" {
+			log.Printf("Environment variable %s is not used, did you mean %s?", key, suggestion)
+		} else {
+			log.Printf("Environment variable %s is not used", key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// reportEnvTypos logs the unused environment variables with one of the prefixes, which are similar to a read key.
+func reportEnvTypos(prefixes ...string) {
+	for key, suggestion := range unusedEnv(prefixes...) {
+		if suggestion != "" {
+			log.Printf("Environment variable %s is not used, did you mean %s?", key, suggestion)
+		}
+	}
+}
+
+// unusedEnv returns the unused environment variables with one of the prefixes mapped to the most similar read key
+// (empty if no read key is similar).
+func unusedEnv(prefixes ...string) map[string]string {
+	reads := ConfigReads()
+
+	unused := make(map[string]string)
+	for _, variable := range os.Environ() {
+		key, _, _ := strings.Cut(variable, "=")
+		if _, ok := reads[key]; ok || !hasAnyPrefix(key, prefixes) {
+			continue
+		}
+
+		suggestion, bestDistance := "", maxTypoDistance+1
+		for read := range reads {
+			if distance := editDistance(key, read); distance < bestDistance {
+				suggestion, bestDistance = read, distance
+			}
+		}
+		unused[key] = suggestion
+	}
+
+	return unused
+}
+
+// hasAnyPrefix reports whether the key starts with one of the prefixes.
+func hasAnyPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance returns the Levenshtein distance between the strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
@@ -0,0 +1,114 @@
+package otelHelper
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	exportObservers   []func(latency time.Duration, err error)
+	exportObserversMu sync.RWMutex
+)
+
+// OnExportLatency registers an observer which is called after every span export with the time the export took and
+// its error (if any). It enables the detection of a saturated telemetry pipeline.
+func OnExportLatency(observer func(latency time.Duration, err error)) {
+	exportObserversMu.Lock()
+	defer exportObserversMu.Unlock()
+
+	exportObservers = append(exportObservers, observer)
+}
+
+// notifyExportLatency calls all registered export observers.
+func notifyExportLatency(latency time.Duration, err error) {
+	exportObserversMu.RLock()
+	defer exportObserversMu.RUnlock()
+
+	for _, observer := range exportObservers {
+		observer(latency, err)
+	}
+}
+
+// monitoredExporter is a span exporter which measures the latency of the wrapped exporter.
+type monitoredExporter struct {
+	trace.SpanExporter
+}
+
+// ExportSpans exports the spans with the wrapped exporter and notifies the export observers.
+func (e monitoredExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	start := time.Now()
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	notifyExportLatency(time.Since(start), err)
+	recordExportStats(spans, err)
+	recordExportResult(err)
+
+	return err
+}
+
+// maxTrackedRoutes bounds the number of span names tracked for the slowest routes.
+const maxTrackedRoutes = 1000
+
+var (
+	spansExported atomic.Int64
+	spansFailed   atomic.Int64
+
+	routesMu       sync.Mutex
+	routeLatencies = make(map[string]time.Duration) // Maximum duration of the server spans by name
+)
+
+// RouteLatency is the maximum duration of the server spans with the name (e.g. the route).
+type RouteLatency struct {
+	Name string
+	Max  time.Duration
+}
+
+// ExportStats summarizes the span exports of the process lifetime.
+type ExportStats struct {
+	SpansExported int64
+	SpansFailed   int64          // Spans dropped due to failed exports
+	SlowestRoutes []RouteLatency // Slowest server spans, ordered from the slowest
+}
+
+// GetExportStats returns the export statistics with the n slowest routes.
+func GetExportStats(n int) ExportStats {
+	routesMu.Lock()
+	routes := make([]RouteLatency, 0, len(routeLatencies))
+	for name, latency := range routeLatencies {
+		routes = append(routes, RouteLatency{Name: name, Max: latency})
+	}
+	routesMu.Unlock()
+
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Max > routes[j].Max })
+	if len(routes) > n {
+		routes = routes[:n]
+	}
+
+	return ExportStats{SpansExported: spansExported.Load(), SpansFailed: spansFailed.Load(), SlowestRoutes: routes}
+}
+
+// recordExportStats counts the exported spans and tracks the durations of the server spans.
+func recordExportStats(spans []trace.ReadOnlySpan, err error) {
+	if err != nil {
+		spansFailed.Add(int64(len(spans)))
+		return
+	}
+	spansExported.Add(int64(len(spans)))
+
+	routesMu.Lock()
+	defer routesMu.Unlock()
+
+	for _, span := range spans {
+		if span.SpanKind() != oteltrace.SpanKindServer {
+			continue
+		}
+		duration := span.EndTime().Sub(span.StartTime())
+		if latency, ok := routeLatencies[span.Name()]; ok || len(routeLatencies) < maxTrackedRoutes {
+			routeLatencies[span.Name()] = max(latency, duration)
+		}
+	}
+}
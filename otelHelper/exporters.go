@@ -0,0 +1,303 @@
+package otelHelper
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Span exporters of OTEL_TRACES_EXPORTER.
+const (
+	ExporterOTLP    = "otlp"
+	ExporterConsole = "console"
+	ExporterJaeger  = "jaeger"
+	ExporterZipkin  = "zipkin"
+	ExporterNone    = "none"
+)
+
+// namedSpanExporter is an additional span exporter of the tracer provider.
+type namedSpanExporter struct {
+	name     string
+	exporter trace.SpanExporter
+}
+
+var (
+	traceExportersMu sync.Mutex
+	traceExporters   []namedSpanExporter
+)
+
+// AddTraceExporter adds a span exporter to the tracer provider next to the OTLP exporter, e.g. to print the spans for
+// debugging or to export them to two collectors during a migration. Each exporter is batched and shut down
+// independently (its component is named "trace exporter <name>"). It has to be called before SetupOtelHelper.
+func AddTraceExporter(name string, exporter trace.SpanExporter) {
+	traceExportersMu.Lock()
+	defer traceExportersMu.Unlock()
+
+	traceExporters = append(traceExporters, namedSpanExporter{name: name, exporter: exporter})
+}
+
+// getTraceExporters returns the additional span exporters.
+func getTraceExporters() []namedSpanExporter {
+	traceExportersMu.Lock()
+	defer traceExportersMu.Unlock()
+
+	return append([]namedSpanExporter(nil), traceExporters...)
+}
+
+// getTracesExporterNames returns the span exporters of OTEL_TRACES_EXPORTER (default: otlp).
+func getTracesExporterNames() map[string]bool {
+	value := Getenv("OTEL_TRACES_EXPORTER")
+	if strings.TrimSpace(value) == "" {
+		return map[string]bool{ExporterOTLP: true}
+	}
+
+	names := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case ExporterOTLP, ExporterConsole, ExporterJaeger, ExporterZipkin:
+			names[name] = true
+		case ExporterNone, "":
+		default:
+			log.Printf("Unsupported exporter %q in OTEL_TRACES_EXPORTER, skipping it", name)
+		}
+	}
+	return names
+}
+
+// newEnvTraceExporters creates the span exporters of OTEL_TRACES_EXPORTER besides OTLP.
+func newEnvTraceExporters(names map[string]bool) ([]namedSpanExporter, error) {
+	var exporters []namedSpanExporter
+	if names[ExporterConsole] {
+		// Pretty-print the spans to stdout, so that they can be inspected locally without a collector
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create the console trace exporter")
+		}
+		exporters = append(exporters, namedSpanExporter{name: ExporterConsole, exporter: exporter})
+	}
+	if names[ExporterJaeger] {
+		exporter, err := NewJaegerExporter(JaegerConfigFromEnv())
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create the Jaeger trace exporter")
+		}
+		exporters = append(exporters, namedSpanExporter{name: ExporterJaeger, exporter: exporter})
+	}
+	if names[ExporterZipkin] {
+		exporter, err := NewZipkinExporter(ZipkinConfigFromEnv())
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create the Zipkin trace exporter")
+		}
+		exporters = append(exporters, namedSpanExporter{name: ExporterZipkin, exporter: exporter})
+	}
+	return exporters, nil
+}
+
+// Transport protocols of the OTLP exporters (OTEL_EXPORTER_OTLP_PROTOCOL).
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+)
+
+// getProtocol returns the transport protocol of the OTLP exporters from OTEL_EXPORTER_OTLP_PROTOCOL (default: gRPC).
+// HTTP is an alternative for environments where gRPC egress is blocked by proxies or service meshes.
+func getProtocol() string {
+	switch protocol := strings.ToLower(Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")); protocol {
+	case "", ProtocolGRPC:
+		return ProtocolGRPC
+	case ProtocolHTTP:
+		return ProtocolHTTP
+	default:
+		log.Printf("Unsupported OTEL_EXPORTER_OTLP_PROTOCOL %q, using grpc", protocol)
+		return ProtocolGRPC
+	}
+}
+
+// newOTLPTraceExporter creates the OTLP span exporter sending to the collector with the configured protocol.
+func newOTLPTraceExporter(collectorURL string, supportTLS bool, config SpanExportConfig) (trace.SpanExporter, error) {
+	if getProtocol() == ProtocolHTTP {
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(collectorURL),
+			otlptracehttp.WithHeaders(getExporterHeaders()),
+			otlptracehttp.WithRetry(otlptracehttp.RetryConfig{
+				Enabled:         !config.RetryDisabled,
+				InitialInterval: config.RetryInitialInterval,
+				MaxInterval:     config.RetryMaxInterval,
+				MaxElapsedTime:  config.RetryMaxElapsedTime,
+			}),
+		}
+		if getCompression() == CompressionGzip {
+			opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+		}
+		if !supportTLS {
+			opts = append(opts, otlptracehttp.WithInsecure())
+			log.Println("Insecure connection to the collector")
+		} else {
+			tlsConfig, err := newTLSConfig()
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to set up the TLS connection")
+			}
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+		}
+
+		exporter, err := otlptracehttp.New(context.Background(), opts...)
+		return exporter, errors.Wrap(err, "Failed to create OTLP HTTP exporter")
+	}
+
+	// Create a slice to hold the exporter options
+	var opts []otlptracegrpc.Option
+
+	// Add the collector URL to the exporter options
+	opts = append(opts, otlptracegrpc.WithEndpoint(collectorURL))
+
+	// Add the headers (e.g. the access token of the vendor) and the dial options
+	opts = append(opts, otlptracegrpc.WithHeaders(getExporterHeaders()))
+	opts = append(opts, otlptracegrpc.WithDialOption(getDialOptions()...))
+
+	// Retry failed exports with exponential backoff, so that spans survive a restart of the collector
+	opts = append(opts, otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig{
+		Enabled:         !config.RetryDisabled,
+		InitialInterval: config.RetryInitialInterval,
+		MaxInterval:     config.RetryMaxInterval,
+		MaxElapsedTime:  config.RetryMaxElapsedTime,
+	}))
+
+	// Track the state of the connection to the collector for HealthCheck
+	opts = append(opts, otlptracegrpc.WithDialOption(grpc.WithStatsHandler(connectionStatsHandler{})))
+
+	// Compress the payloads if configured (the exporter rejects "none" as compressor name)
+	if getCompression() == CompressionGzip {
+		opts = append(opts, otlptracegrpc.WithCompressor(CompressionGzip))
+	}
+
+	// If the connection is insecure, add the insecure option to the exporter options
+	if !supportTLS { // Thanks to Levin for pointing out the missing exclamation mark
+		opts = append(opts, otlptracegrpc.WithInsecure())
+		log.Println("Insecure connection to the collector")
+	} else {
+		tlsConfig, err := newTLSConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to set up the TLS connection")
+		}
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	exporter, err := otlptracegrpc.New(context.Background(), opts...)
+	return exporter, errors.Wrap(err, "Failed to create OTLP exporter")
+}
+
+// newOTLPLogExporter creates the OTLP log exporter sending to the collector with the configured protocol.
+func newOTLPLogExporter(collectorURL string, supportTLS bool, config LogExportConfig) (sdklog.Exporter, error) {
+	if getProtocol() == ProtocolHTTP {
+		opts := []otlploghttp.Option{
+			otlploghttp.WithEndpoint(collectorURL),
+			otlploghttp.WithHeaders(getExporterHeaders()),
+			otlploghttp.WithRetry(otlploghttp.RetryConfig{
+				Enabled:         true,
+				InitialInterval: config.RetryInitialInterval,
+				MaxInterval:     config.RetryMaxInterval,
+				MaxElapsedTime:  config.RetryMaxElapsedTime,
+			}),
+		}
+		if getCompression() == CompressionGzip {
+			opts = append(opts, otlploghttp.WithCompression(otlploghttp.GzipCompression))
+		}
+		if !supportTLS {
+			opts = append(opts, otlploghttp.WithInsecure())
+		} else {
+			tlsConfig, err := newTLSConfig()
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to set up the TLS connection")
+			}
+			opts = append(opts, otlploghttp.WithTLSClientConfig(tlsConfig))
+		}
+
+		exporter, err := otlploghttp.New(context.Background(), opts...)
+		return exporter, errors.Wrap(err, "Failed to create OTLP HTTP log exporter")
+	}
+
+	opts := []otlploggrpc.Option{
+		otlploggrpc.WithEndpoint(collectorURL),
+		otlploggrpc.WithHeaders(getExporterHeaders()),
+		otlploggrpc.WithDialOption(getDialOptions()...),
+		otlploggrpc.WithCompressor(getCompression()),
+		otlploggrpc.WithRetry(otlploggrpc.RetryConfig{
+			Enabled:         true,
+			InitialInterval: config.RetryInitialInterval,
+			MaxInterval:     config.RetryMaxInterval,
+			MaxElapsedTime:  config.RetryMaxElapsedTime,
+		}),
+	}
+	if !supportTLS {
+		opts = append(opts, otlploggrpc.WithInsecure())
+	} else {
+		tlsConfig, err := newTLSConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to set up the TLS connection")
+		}
+		opts = append(opts, otlploggrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	exporter, err := otlploggrpc.New(context.Background(), opts...)
+	return exporter, errors.Wrap(err, "Failed to create OTLP log exporter")
+}
+
+// newOTLPMetricExporter creates the OTLP metric exporter sending to the collector with the configured protocol.
+func newOTLPMetricExporter(collectorURL string, supportTLS bool) (sdkmetric.Exporter, error) {
+	if getProtocol() == ProtocolHTTP {
+		opts := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(collectorURL),
+			otlpmetrichttp.WithHeaders(getExporterHeaders()),
+		}
+		if getCompression() == CompressionGzip {
+			opts = append(opts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+		}
+		if !supportTLS {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		} else {
+			tlsConfig, err := newTLSConfig()
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to set up the TLS connection")
+			}
+			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+		}
+
+		exporter, err := otlpmetrichttp.New(context.Background(), opts...)
+		return exporter, errors.Wrap(err, "Failed to create OTLP HTTP metric exporter")
+	}
+
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(collectorURL),
+		otlpmetricgrpc.WithHeaders(getExporterHeaders()),
+		otlpmetricgrpc.WithDialOption(getDialOptions()...),
+	}
+	if getCompression() == CompressionGzip {
+		opts = append(opts, otlpmetricgrpc.WithCompressor(CompressionGzip)) // The exporter rejects "none"
+	}
+	if !supportTLS {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	} else {
+		tlsConfig, err := newTLSConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to set up the TLS connection")
+		}
+		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	exporter, err := otlpmetricgrpc.New(context.Background(), opts...)
+	return exporter, errors.Wrap(err, "Failed to create OTLP metric exporter")
+}
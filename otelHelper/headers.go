@@ -0,0 +1,58 @@
+package otelHelper
+
+import (
+	"google.golang.org/grpc"
+	"sync"
+)
+
+var (
+	exporterConfigMu sync.Mutex
+	exporterHeaders  = make(map[string]string)
+	dialOptions      []grpc.DialOption
+)
+
+// WithExporterHeaders adds headers to the requests of the OTLP exporters, e.g. the access token or tenant header of
+// a hosted observability vendor. They take precedence over OTEL_EXPORTER_OTLP_HEADERS. It has to be called before
+// SetupOtelHelper.
+func WithExporterHeaders(headers map[string]string) {
+	exporterConfigMu.Lock()
+	defer exporterConfigMu.Unlock()
+
+	for key, value := range headers {
+		exporterHeaders[key] = value
+	}
+}
+
+// WithDialOptions adds options to the gRPC connections of the OTLP exporters, e.g. interceptors or keepalive
+// parameters. It has to be called before SetupOtelHelper.
+func WithDialOptions(opts ...grpc.DialOption) {
+	exporterConfigMu.Lock()
+	defer exporterConfigMu.Unlock()
+
+	dialOptions = append(dialOptions, opts...)
+}
+
+// getExporterHeaders returns the headers of OTEL_EXPORTER_OTLP_HEADERS merged with the ones added with
+// WithExporterHeaders.
+func getExporterHeaders() map[string]string {
+	headers := make(map[string]string)
+	parseKeyValues("OTEL_EXPORTER_OTLP_HEADERS", func(key, value string) {
+		headers[key] = value
+	})
+
+	exporterConfigMu.Lock()
+	defer exporterConfigMu.Unlock()
+
+	for key, value := range exporterHeaders {
+		headers[key] = value
+	}
+	return headers
+}
+
+// getDialOptions returns the dial options added with WithDialOptions.
+func getDialOptions() []grpc.DialOption {
+	exporterConfigMu.Lock()
+	defer exporterConfigMu.Unlock()
+
+	return append([]grpc.DialOption(nil), dialOptions...)
+}
@@ -0,0 +1,150 @@
+package otelHelper
+
+import (
+	"context"
+	cryptoRand "crypto/rand"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/stats"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CollectorUnhealthyError is returned by HealthCheck if the telemetry cannot be exported to the collector.
+var CollectorUnhealthyError = errors.New("Collector unhealthy")
+
+// healthTarget is the OTLP span exporter checked by HealthCheck with the resource of the probe spans.
+type healthTarget struct {
+	exporter trace.SpanExporter
+	resource *resource.Resource
+}
+
+var (
+	health        atomic.Pointer[healthTarget] // Nil if no collector is configured
+	healthProbe   atomic.Bool
+	lastExportErr atomic.Pointer[error] // Error of the last span export, nil if it succeeded
+
+	connected            atomic.Bool // Whether the gRPC connection to the collector is established
+	connectionSeen       atomic.Bool // Whether the connection has been established at least once
+	connectionObservers  []func(connected bool)
+	connectionObserverMu sync.RWMutex
+)
+
+// SetHealthCheckProbe enables sending a probe span to the collector on every HealthCheck, which detects an
+// unreachable collector before the next regular export. The probe spans are named "otelHelper health check".
+func SetHealthCheckProbe(enabled bool) {
+	healthProbe.Store(enabled)
+}
+
+// OnConnectionStateChange registers an observer which is called whenever the gRPC connection of the span exporter to
+// the collector is established or lost.
+func OnConnectionStateChange(observer func(connected bool)) {
+	connectionObserverMu.Lock()
+	defer connectionObserverMu.Unlock()
+
+	connectionObservers = append(connectionObservers, observer)
+}
+
+// HealthCheck returns CollectorUnhealthyError if the last span export failed, the gRPC connection to the collector
+// has been lost or the probe span (refer to SetHealthCheckProbe) cannot be exported, so that readiness probes can
+// reflect the health of the telemetry pipeline. Without a collector, the check always succeeds.
+func HealthCheck(ctx context.Context) error {
+	target := health.Load()
+	if target == nil {
+		return nil
+	}
+
+	if healthProbe.Load() {
+		if err := target.exporter.ExportSpans(ctx, []trace.ReadOnlySpan{newProbeSpan(target.resource)}); err != nil {
+			return errors.Wrapf(CollectorUnhealthyError, "Failed to export the probe span: %v", err)
+		}
+		return nil
+	}
+
+	if err := lastExportErr.Load(); err != nil {
+		return errors.Wrapf(CollectorUnhealthyError, "The last export failed: %v", *err)
+	}
+	if getProtocol() == ProtocolGRPC && !connected.Load() && connectionSeen.Load() {
+		return errors.Wrap(CollectorUnhealthyError, "The connection to the collector has been lost")
+	}
+	return nil
+}
+
+// recordExportResult records the result of a span export for HealthCheck.
+func recordExportResult(err error) {
+	if err != nil {
+		lastExportErr.Store(&err)
+	} else {
+		lastExportErr.Store(nil)
+	}
+}
+
+// newProbeSpan returns an ended root span probing the export to the collector.
+func newProbeSpan(res *resource.Resource) trace.ReadOnlySpan {
+	var traceID oteltrace.TraceID
+	var spanID oteltrace.SpanID
+	_, _ = cryptoRand.Read(traceID[:])
+	_, _ = cryptoRand.Read(spanID[:])
+
+	now := time.Now()
+	return tracetest.SpanStub{
+		Name: "otelHelper health check",
+		SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: oteltrace.FlagsSampled,
+		}),
+		SpanKind:             oteltrace.SpanKindInternal,
+		StartTime:            now,
+		EndTime:              now,
+		Attributes:           []attribute.KeyValue{attribute.Bool("otelhelper.probe", true)},
+		Resource:             res,
+		InstrumentationScope: instrumentation.Scope{Name: "github.com/LucaSchmitz2003/FlowWatch/otelHelper"},
+	}.Snapshot()
+}
+
+// connectionStatsHandler tracks the state of the gRPC connection to the collector.
+type connectionStatsHandler struct{}
+
+// TagRPC returns the context unchanged.
+func (connectionStatsHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC ignores the RPC stats.
+func (connectionStatsHandler) HandleRPC(context.Context, stats.RPCStats) {}
+
+// TagConn returns the context unchanged.
+func (connectionStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn records the connection state and notifies the observers of changes.
+func (connectionStatsHandler) HandleConn(_ context.Context, connStats stats.ConnStats) {
+	var state bool
+	switch connStats.(type) {
+	case *stats.ConnBegin:
+		state = true
+		connectionSeen.Store(true)
+	case *stats.ConnEnd:
+		state = false
+	default:
+		return
+	}
+	if connected.Swap(state) == state {
+		return
+	}
+
+	connectionObserverMu.RLock()
+	defer connectionObserverMu.RUnlock()
+
+	for _, observer := range connectionObservers {
+		observer(state)
+	}
+}
@@ -0,0 +1,27 @@
+package otelHelper
+
+import "github.com/pkg/errors"
+
+// JaegerUnavailableError is returned by NewJaegerExporter if the program has been built without the jaeger build tag.
+var JaegerUnavailableError = errors.New("Jaeger exporter not included in the build")
+
+// JaegerConfig configures the span exporter for Jaeger backends which do not accept OTLP yet. The exporter of
+// OpenTelemetry is deprecated, so it is only included if the program is built with the jaeger build tag.
+type JaegerConfig struct {
+	Endpoint  string // Collector HTTP endpoint, e.g. http://jaeger:14268/api/traces (the agent is used if empty)
+	Username  string // Basic authentication of the collector endpoint
+	Password  string // Basic authentication of the collector endpoint
+	AgentHost string // Host of the agent receiving the spans via UDP (default: localhost)
+	AgentPort string // Port of the agent (default: 6831)
+}
+
+// JaegerConfigFromEnv reads the JaegerConfig from the standard environment variables.
+func JaegerConfigFromEnv() JaegerConfig {
+	return JaegerConfig{
+		Endpoint:  Getenv("OTEL_EXPORTER_JAEGER_ENDPOINT"),
+		Username:  Getenv("OTEL_EXPORTER_JAEGER_USER"),
+		Password:  Getenv("OTEL_EXPORTER_JAEGER_PASSWORD"),
+		AgentHost: Getenv("OTEL_EXPORTER_JAEGER_AGENT_HOST"),
+		AgentPort: Getenv("OTEL_EXPORTER_JAEGER_AGENT_PORT"),
+	}
+}
@@ -0,0 +1,33 @@
+//go:build jaeger
+
+package otelHelper
+
+import (
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"log"
+)
+
+// NewJaegerExporter creates a span exporter sending to the Jaeger collector via HTTP or, without an endpoint, to the
+// Jaeger agent via UDP. It can be added with AddTraceExporter or selected with OTEL_TRACES_EXPORTER=jaeger.
+func NewJaegerExporter(config JaegerConfig) (trace.SpanExporter, error) {
+	if config.Endpoint != "" {
+		return jaeger.New(jaeger.WithCollectorEndpoint(
+			jaeger.WithEndpoint(config.Endpoint),
+			jaeger.WithUsername(config.Username),
+			jaeger.WithPassword(config.Password),
+		))
+	}
+
+	if config.AgentHost == "" {
+		config.AgentHost = "localhost"
+	}
+	if config.AgentPort == "" {
+		config.AgentPort = "6831"
+	}
+	return jaeger.New(jaeger.WithAgentEndpoint(
+		jaeger.WithAgentHost(config.AgentHost),
+		jaeger.WithAgentPort(config.AgentPort),
+		jaeger.WithLogger(log.Default()),
+	))
+}
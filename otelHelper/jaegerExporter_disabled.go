@@ -0,0 +1,14 @@
+//go:build !jaeger
+
+package otelHelper
+
+import (
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// NewJaegerExporter returns JaegerUnavailableError, since the deprecated Jaeger exporter is only included if the
+// program is built with the jaeger build tag (go build -tags jaeger).
+func NewJaegerExporter(JaegerConfig) (trace.SpanExporter, error) {
+	return nil, errors.Wrap(JaegerUnavailableError, "Rebuild with -tags jaeger or export via OTLP")
+}
@@ -0,0 +1,158 @@
+package otelHelper
+
+import (
+	"context"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"log"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of the log record export (the defaults of the batch log record processor of the specification).
+const (
+	DefaultLogMaxQueueSize   = 2048
+	DefaultLogExportInterval = time.Second
+	DefaultLogMaxBatchSize   = 512
+	DefaultLogExportTimeout  = 30 * time.Second
+)
+
+// LogExportConfig configures the batching, queueing and retries of the exported log records. Zero values are read
+// from the OTEL_BLRP_ variables of the specification or set to the defaults.
+type LogExportConfig struct {
+	MaxQueueSize   int           // Records waiting for the export, further records are dropped (OTEL_BLRP_MAX_QUEUE_SIZE)
+	ExportInterval time.Duration // Interval of the exports (OTEL_BLRP_SCHEDULE_DELAY in milliseconds)
+	MaxBatchSize   int           // Records per export (OTEL_BLRP_MAX_EXPORT_BATCH_SIZE)
+	ExportTimeout  time.Duration // Timeout of an export including its retries (OTEL_BLRP_EXPORT_TIMEOUT in milliseconds)
+
+	RetryInitialInterval time.Duration // Delay before the first retry, increased exponentially (default: 5s)
+	RetryMaxInterval     time.Duration // Upper bound of the delay between retries (default: 30s)
+	RetryMaxElapsedTime  time.Duration // Time after which a failed export is given up (default: 1m)
+}
+
+var logExportConfig atomic.Pointer[LogExportConfig]
+
+// SetLogExportConfig configures the export of the log records. It has to be called before SetupOtelHelper.
+func SetLogExportConfig(config LogExportConfig) {
+	logExportConfig.Store(&config)
+}
+
+// getLogExportConfig returns the configured log export config with the unset values resolved.
+func getLogExportConfig() LogExportConfig {
+	var config LogExportConfig
+	if configured := logExportConfig.Load(); configured != nil {
+		config = *configured
+	}
+
+	if config.MaxQueueSize <= 0 {
+		config.MaxQueueSize = envInt("OTEL_BLRP_MAX_QUEUE_SIZE", DefaultLogMaxQueueSize)
+	}
+	if config.ExportInterval <= 0 {
+		config.ExportInterval = time.Duration(envInt("OTEL_BLRP_SCHEDULE_DELAY",
+			int(DefaultLogExportInterval.Milliseconds()))) * time.Millisecond
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = envInt("OTEL_BLRP_MAX_EXPORT_BATCH_SIZE", DefaultLogMaxBatchSize)
+	}
+	config.MaxBatchSize = min(config.MaxBatchSize, config.MaxQueueSize)
+	if config.ExportTimeout <= 0 {
+		config.ExportTimeout = time.Duration(envInt("OTEL_BLRP_EXPORT_TIMEOUT",
+			int(DefaultLogExportTimeout.Milliseconds()))) * time.Millisecond
+	}
+	if config.RetryInitialInterval <= 0 {
+		config.RetryInitialInterval = 5 * time.Second
+	}
+	if config.RetryMaxInterval <= 0 {
+		config.RetryMaxInterval = 30 * time.Second
+	}
+	if config.RetryMaxElapsedTime <= 0 {
+		config.RetryMaxElapsedTime = time.Minute
+	}
+
+	return config
+}
+
+// envInt returns the positive integer of the environment variable or the default value.
+func envInt(key string, defaultValue int) int {
+	value := Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("Failed to parse %s, using default. %v", key, err)
+		return defaultValue
+	}
+	return parsed
+}
+
+// newLogProcessor returns the batch processor exporting the records with the exporter, bounded by the queue size.
+func (config LogExportConfig) newLogProcessor(exporter sdklog.Exporter) sdklog.Processor {
+	processor := &boundedLogProcessor{maxQueueSize: int64(config.MaxQueueSize)}
+	processor.Processor = sdklog.NewBatchProcessor(countingLogExporter{Exporter: exporter, processor: processor},
+		sdklog.WithMaxQueueSize(config.MaxQueueSize),
+		sdklog.WithExportInterval(config.ExportInterval),
+		sdklog.WithExportMaxBatchSize(config.MaxBatchSize),
+		sdklog.WithExportTimeout(config.ExportTimeout),
+	)
+	return processor
+}
+
+var (
+	logRecordsExported atomic.Int64
+	logRecordsFailed   atomic.Int64
+	logRecordsDropped  atomic.Int64
+)
+
+// LogExportStats summarizes the log record exports of the process lifetime.
+type LogExportStats struct {
+	RecordsExported int64
+	RecordsFailed   int64 // Records lost because the export failed after all retries
+	RecordsDropped  int64 // Records dropped because the queue was full
+}
+
+// GetLogExportStats returns the log export statistics, e.g. to alert on lost records of bursty workloads.
+func GetLogExportStats() LogExportStats {
+	return LogExportStats{
+		RecordsExported: logRecordsExported.Load(),
+		RecordsFailed:   logRecordsFailed.Load(),
+		RecordsDropped:  logRecordsDropped.Load(),
+	}
+}
+
+// boundedLogProcessor drops and counts the records exceeding the queue size instead of blocking the logging
+// goroutine, so that bursts cannot exhaust the memory.
+type boundedLogProcessor struct {
+	sdklog.Processor
+	maxQueueSize int64
+	pending      atomic.Int64 // Records emitted but not exported yet
+}
+
+// OnEmit passes the record to the batch processor unless the queue is full.
+func (p *boundedLogProcessor) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	if p.pending.Add(1) > p.maxQueueSize {
+		p.pending.Add(-1)
+		logRecordsDropped.Add(1)
+		return nil
+	}
+	return p.Processor.OnEmit(ctx, record)
+}
+
+// countingLogExporter is a log exporter counting the exported and failed records.
+type countingLogExporter struct {
+	sdklog.Exporter
+	processor *boundedLogProcessor
+}
+
+// Export exports the records with the wrapped exporter and counts them.
+func (e countingLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	err := e.Exporter.Export(ctx, records)
+	e.processor.pending.Add(-int64(len(records)))
+	if err != nil {
+		logRecordsFailed.Add(int64(len(records)))
+	} else {
+		logRecordsExported.Add(int64(len(records)))
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package otelHelper
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"log"
+)
+
+// initLogProvider sets up the global logger provider exporting the log records to the collector, so that entries
+// without a surrounding span are not lost. Without a collector URL the no-op provider of the API is kept.
+func initLogProvider(serviceName, collectorURL string, supportTLS bool, resourceAttributes ...attribute.KeyValue) error {
+	if collectorURL == "" {
+		log.Println("Collector URL not provided, skipping log exporter initialization")
+		return nil
+	}
+
+	config := getLogExportConfig()
+	exporter, err := newOTLPLogExporter(collectorURL, supportTLS, config)
+	if err != nil {
+		return err
+	}
+
+	var logExporter sdklog.Exporter = exporter
+	if spool := newSignalSpool("logs"); spool != nil {
+		logExporter = spoolingLogExporter{Exporter: logExporter, spool: spool} // Keep the records of failed exports
+	}
+
+	lp := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(config.newLogProcessor(logExporter)),
+		sdklog.WithResource(newResource(serviceName, resourceAttributes...)),
+	)
+	global.SetLoggerProvider(lp)
+
+	// The provider shuts down its processors and thereby the exporter
+	RegisterShutdown("logger provider", DefaultShutdownBudget, func(ctx context.Context) error {
+		return errors.Wrap(lp.Shutdown(ctx), "Failed to shut down the logger provider")
+	})
+	flushFuncs = append(flushFuncs, func(ctx context.Context) error {
+		return errors.Wrap(lp.ForceFlush(ctx), "Failed to flush the logger provider")
+	})
+
+	return nil
+}
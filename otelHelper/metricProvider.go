@@ -0,0 +1,94 @@
+package otelHelper
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of the metric export (the defaults of the periodic exporting metric reader of the specification).
+const (
+	DefaultMetricExportInterval = time.Minute
+	DefaultMetricExportTimeout  = 30 * time.Second
+)
+
+// MetricExportConfig configures the periodic export of the metrics. Zero values are read from the OTEL_METRIC_EXPORT_
+// variables of the specification or set to the defaults.
+type MetricExportConfig struct {
+	ExportInterval time.Duration // Interval of the exports (OTEL_METRIC_EXPORT_INTERVAL in milliseconds)
+	ExportTimeout  time.Duration // Timeout of an export (OTEL_METRIC_EXPORT_TIMEOUT in milliseconds)
+}
+
+var metricExportConfig atomic.Pointer[MetricExportConfig]
+
+// SetMetricExportConfig configures the export of the metrics. It has to be called before SetupOtelHelper.
+func SetMetricExportConfig(config MetricExportConfig) {
+	metricExportConfig.Store(&config)
+}
+
+// getMetricExportConfig returns the configured metric export config with the unset values resolved.
+func getMetricExportConfig() MetricExportConfig {
+	var config MetricExportConfig
+	if configured := metricExportConfig.Load(); configured != nil {
+		config = *configured
+	}
+
+	if config.ExportInterval <= 0 {
+		config.ExportInterval = time.Duration(envInt("OTEL_METRIC_EXPORT_INTERVAL",
+			int(DefaultMetricExportInterval.Milliseconds()))) * time.Millisecond
+	}
+	if config.ExportTimeout <= 0 {
+		config.ExportTimeout = time.Duration(envInt("OTEL_METRIC_EXPORT_TIMEOUT",
+			int(DefaultMetricExportTimeout.Milliseconds()))) * time.Millisecond
+	}
+
+	return config
+}
+
+// initMeterProvider sets up the global meter provider exporting the metrics to the collector periodically and serving
+// them to Prometheus if enabled. Without a collector URL or Prometheus the no-op provider of the API is kept.
+func initMeterProvider(serviceName, collectorURL string, supportTLS bool, resourceAttributes ...attribute.KeyValue) error {
+	prometheusReader := getPrometheusReader()
+	if collectorURL == "" && prometheusReader == nil {
+		log.Println("Collector URL not provided, skipping metric exporter initialization")
+		return nil
+	}
+
+	mpOptions := []sdkmetric.Option{sdkmetric.WithResource(newResource(serviceName, resourceAttributes...))}
+
+	// Export the metrics to the collector periodically
+	if collectorURL != "" {
+		config := getMetricExportConfig()
+		exporter, err := newOTLPMetricExporter(collectorURL, supportTLS)
+		if err != nil {
+			return err
+		}
+		mpOptions = append(mpOptions, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter,
+			sdkmetric.WithInterval(config.ExportInterval),
+			sdkmetric.WithTimeout(config.ExportTimeout),
+		)))
+	}
+
+	// Collect the metrics on every scrape of the Prometheus endpoint
+	if prometheusReader != nil {
+		mpOptions = append(mpOptions, sdkmetric.WithReader(prometheusReader))
+	}
+
+	mp := sdkmetric.NewMeterProvider(mpOptions...)
+	otel.SetMeterProvider(mp)
+
+	// The provider shuts down its readers and thereby the exporter after a final export
+	RegisterShutdown("meter provider", DefaultShutdownBudget, func(ctx context.Context) error {
+		return errors.Wrap(mp.Shutdown(ctx), "Failed to shut down the meter provider")
+	})
+	flushFuncs = append(flushFuncs, func(ctx context.Context) error {
+		return errors.Wrap(mp.ForceFlush(ctx), "Failed to flush the meter provider")
+	})
+
+	return nil
+}
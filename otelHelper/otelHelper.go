@@ -1,27 +1,29 @@
 package otelHelper
 
 import (
+	"context"
 	"github.com/joho/godotenv"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 	"log"
-	"os"
 	"strconv"
 	"sync"
 )
 
 var (
-	shutdownFuncs []func() error
-	once          sync.Once
+	flushFuncs []func(ctx context.Context) error
+	once       sync.Once
 )
 
-func Shutdown() {
-	for _, shutdown := range shutdownFuncs {
-		err := shutdown()
-		if err != nil {
-			log.Printf("Failed to shut down the service. %v", err)
+// ForceFlush exports all pending telemetry without shutting down the providers, e.g. before a short-lived program
+// exits normally.
+func ForceFlush(ctx context.Context) error {
+	for _, flush := range flushFuncs {
+		if err := flush(ctx); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
 // initOtelHelper initializes the trace-, metric- & log-provider.
@@ -36,31 +38,59 @@ func initOtelHelper() {
 	// Load the environment variables to make sure that the settings have already been loaded
 	_ = godotenv.Load(".env")
 
-	// Get the service name from the environment variables
-	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	// Get the resource attributes of OTEL_RESOURCE_ATTRIBUTES and WithResourceAttributes
+	attributes := getResourceAttributes()
+
+	// Get the service name from the environment variables, falling back to the service.name resource attribute
+	serviceName := Getenv("OTEL_SERVICE_NAME")
 	if serviceName == "" {
-		serviceName = "TestService"
-		log.Println("OTEL_SERVICE_NAME not set, using default")
+		if name, ok := resourceServiceName(attributes); ok {
+			serviceName = name
+		} else {
+			serviceName = "TestService"
+			log.Println("OTEL_SERVICE_NAME not set, using default")
+		}
 	}
+	tracerName.Store(&serviceName)
 
 	// Get the collector URL from the environment variables
-	collectorURL := os.Getenv("OTEL_COLLECTOR_URL")
+	collectorURL := Getenv("OTEL_COLLECTOR_URL")
 	if collectorURL == "" {
-		log.Println("OTEL_COLLECTOR_URL not set, trace export will be skipped")
+		log.Println("OTEL_COLLECTOR_URL not set, trace export will be skipped (OTEL_TRACES_EXPORTER=console prints the spans)")
 	}
 
 	// Get the tls support state from the environment variables
-	supportTLS, err := strconv.ParseBool(os.Getenv("OTEL_SUPPORT_TLS"))
+	supportTLS, err := strconv.ParseBool(Getenv("OTEL_SUPPORT_TLS"))
 	if err != nil {
 		supportTLS = false
 		log.Printf("Failed to parse OTEL_SUPPORT_TLS, using default. %v", err)
 	}
 
+	// Log the runtime limits, which correlate with latency anomalies, and add them to the resource of the spans
+	limits := readCgroupLimits()
+	logCgroupDiagnostics(limits)
+	attributes = append(attributes, limits.attributes()...)
+
+	// Report misspelled variables, which would otherwise silently disable features (e.g. OTEL_COLLETOR_URL)
+	reportEnvTypos("OTEL_")
+
 	// Initialize the trace provider
-	err = initTraceProvider(serviceName, collectorURL, supportTLS)
+	err = initTraceProvider(serviceName, collectorURL, supportTLS, attributes...)
 	if err != nil {
 		log.Fatalf("Failed to set up the trace provider. %v", err)
 	}
+
+	// Initialize the meter provider
+	err = initMeterProvider(serviceName, collectorURL, supportTLS, attributes...)
+	if err != nil {
+		log.Fatalf("Failed to set up the meter provider. %v", err)
+	}
+
+	// Initialize the log provider
+	err = initLogProvider(serviceName, collectorURL, supportTLS, attributes...)
+	if err != nil {
+		log.Fatalf("Failed to set up the log provider. %v", err)
+	}
 }
 
 // SetupOtelHelper initializes the OpenTelemetry SDK connection to the backend if it has not been initialized yet according to the singleton pattern.
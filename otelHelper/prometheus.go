@@ -0,0 +1,35 @@
+package otelHelper
+
+import (
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"net/http"
+	"sync/atomic"
+)
+
+var prometheusExporter atomic.Pointer[otelprometheus.Exporter]
+
+// EnablePrometheus adds a Prometheus reader to the meter provider and returns the handler of the scrape endpoint,
+// which the application mounts on its HTTP server (e.g. at /metrics). The metrics are collected on every scrape, in
+// addition to the OTLP export if a collector is configured. It has to be called before SetupOtelHelper.
+func EnablePrometheus() (http.Handler, error) {
+	registry := prometheus.NewRegistry()
+	exporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create the Prometheus exporter")
+	}
+
+	prometheusExporter.Store(exporter)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{}), nil
+}
+
+// getPrometheusReader returns the reader of EnablePrometheus, nil if it has not been enabled.
+func getPrometheusReader() sdkmetric.Reader {
+	if exporter := prometheusExporter.Load(); exporter != nil {
+		return exporter
+	}
+	return nil
+}
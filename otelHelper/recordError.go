@@ -0,0 +1,60 @@
+package otelHelper
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	errorObservers   []func(ctx context.Context, err error, attributes []attribute.KeyValue)
+	errorObserversMu sync.RWMutex
+	errorRedaction   atomic.Pointer[func(err error) error]
+)
+
+// OnRecordError registers an observer which is called by RecordError after the error has been recorded on the span.
+// FlowWatch registers an observer logging the error, so that the span and the log entry cannot drift apart.
+func OnRecordError(observer func(ctx context.Context, err error, attributes []attribute.KeyValue)) {
+	errorObserversMu.Lock()
+	defer errorObserversMu.Unlock()
+
+	errorObservers = append(errorObservers, observer)
+}
+
+// SetErrorRedaction sets the function masking the sensitive parts of the errors before RecordError records them on
+// the span (nil disables the masking). FlowWatch sets the redaction configured with SetRedaction.
+func SetErrorRedaction(redact func(err error) error) {
+	if redact == nil {
+		errorRedaction.Store(nil)
+		return
+	}
+	errorRedaction.Store(&redact)
+}
+
+// RecordError records the error with the attributes on the active span, sets the status of the span to Error and
+// logs the error with the attributes as fields at error level (if FlowWatch is imported) in one call. Nil errors are
+// ignored.
+func RecordError(ctx context.Context, err error, attributes ...attribute.KeyValue) {
+	if err == nil {
+		return
+	}
+
+	recorded := err
+	if redact := errorRedaction.Load(); redact != nil {
+		recorded = (*redact)(err)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(recorded, trace.WithAttributes(attributes...), trace.WithStackTrace(true))
+	span.SetStatus(codes.Error, recorded.Error())
+
+	errorObserversMu.RLock()
+	defer errorObserversMu.RUnlock()
+
+	for _, observer := range errorObservers {
+		observer(ctx, err, attributes)
+	}
+}
@@ -0,0 +1,113 @@
+package otelHelper
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var (
+	resourceMu         sync.Mutex
+	resourceAttributes []attribute.KeyValue
+
+	detectOnce sync.Once
+	detected   *resource.Resource // Attributes of the resource detectors, nil if none are enabled
+)
+
+// WithResourceAttributes adds attributes to the resource of the spans and exported log records, e.g.
+// deployment.environment, service.version or a team tag. They take precedence over OTEL_RESOURCE_ATTRIBUTES. It has
+// to be called before SetupOtelHelper.
+func WithResourceAttributes(attributes ...attribute.KeyValue) {
+	resourceMu.Lock()
+	defer resourceMu.Unlock()
+
+	resourceAttributes = append(resourceAttributes, attributes...)
+}
+
+// getResourceAttributes returns the attributes of OTEL_RESOURCE_ATTRIBUTES followed by the ones added with
+// WithResourceAttributes, so that the latter win for duplicate keys.
+func getResourceAttributes() []attribute.KeyValue {
+	var attributes []attribute.KeyValue
+	parseKeyValues("OTEL_RESOURCE_ATTRIBUTES", func(key, value string) {
+		attributes = append(attributes, attribute.String(key, value))
+	})
+
+	resourceMu.Lock()
+	defer resourceMu.Unlock()
+
+	return append(attributes, resourceAttributes...)
+}
+
+// parseKeyValues parses the environment variable as comma-separated list of percent-encoded key=value pairs and
+// passes them to add in order. Invalid pairs are logged and skipped.
+func parseKeyValues(variable string, add func(key, value string)) {
+	for _, pair := range strings.Split(Getenv(variable), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		key, rawValue, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			log.Printf("Invalid pair in %s, skipping it", variable) // The pair is not logged, as it may be a secret
+			continue
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(rawValue))
+		if err != nil {
+			log.Printf("Failed to decode the value of %s in %s, skipping it. %v", key, variable, err)
+			continue
+		}
+		add(key, decoded)
+	}
+}
+
+// resourceServiceName returns the value of service.name among the attributes, if any.
+func resourceServiceName(attributes []attribute.KeyValue) (string, bool) {
+	name, found := "", false
+	for _, kv := range attributes {
+		if kv.Key == semconv.ServiceNameKey {
+			name, found = kv.Value.Emit(), true
+		}
+	}
+	return name, found
+}
+
+// newResource returns the resource of the telemetry with the detected attributes (refer to SetResourceDetectors), the
+// additional attributes and the service name. The explicit attributes take precedence over the detected ones.
+func newResource(serviceName string, attributes ...attribute.KeyValue) *resource.Resource {
+	attributes = append(attributes[:len(attributes):len(attributes)], semconv.ServiceNameKey.String(serviceName))
+
+	detected := detectResource()
+	if detected == nil {
+		return resource.NewWithAttributes(semconv.SchemaURL, attributes...)
+	}
+
+	// The detectors use the schema of the SDK, which is kept by adding the attributes without schema
+	res, err := resource.Merge(detected, resource.NewSchemaless(attributes...))
+	if err != nil {
+		log.Printf("Failed to merge the detected resource attributes. %v", err)
+	}
+	return res
+}
+
+// detectResource runs the enabled detectors once and returns the detected attributes, nil if none are enabled.
+func detectResource() *resource.Resource {
+	detectOnce.Do(func() {
+		options := detectorOptions(getResourceDetectors())
+		if len(options) == 0 {
+			return
+		}
+
+		var err error
+		detected, err = resource.New(context.Background(), options...)
+		if err != nil {
+			log.Printf("Failed to detect some resource attributes. %v", err)
+		}
+	})
+	return detected
+}
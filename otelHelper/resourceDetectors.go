@@ -0,0 +1,140 @@
+package otelHelper
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Resource detectors of SetResourceDetectors and OTEL_RESOURCE_DETECTORS.
+const (
+	DetectorHost       = "host"
+	DetectorProcess    = "process"
+	DetectorContainer  = "container"
+	DetectorKubernetes = "k8s"
+	DetectorAll        = "all"
+)
+
+// serviceAccountNamespaceFile contains the namespace of the pod if a service account token is mounted.
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// InvalidResourceDetectorError is returned if an unknown resource detector is configured.
+var InvalidResourceDetectorError = errors.New("Invalid resource detector")
+
+var resourceDetectors atomic.Pointer[[]string]
+
+// SetResourceDetectors enables the detection of resource attributes (host, process, container, k8s or all), which
+// takes precedence over OTEL_RESOURCE_DETECTORS, so that the spans and log records carry e.g. host.name, container.id
+// or k8s.pod.name without manual wiring. It has to be called before SetupOtelHelper.
+func SetResourceDetectors(names ...string) error {
+	detectors, err := parseResourceDetectors(names)
+	if err != nil {
+		return err
+	}
+
+	resourceDetectors.Store(&detectors)
+	return nil
+}
+
+// parseResourceDetectors normalizes the detector names and expands DetectorAll.
+func parseResourceDetectors(names []string) ([]string, error) {
+	var detectors []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "":
+		case DetectorAll:
+			detectors = append(detectors, DetectorHost, DetectorProcess, DetectorContainer, DetectorKubernetes)
+		case DetectorHost, DetectorProcess, DetectorContainer, DetectorKubernetes:
+			detectors = append(detectors, name)
+		default:
+			return nil, errors.Wrapf(InvalidResourceDetectorError, "Detector %q", name)
+		}
+	}
+	return detectors, nil
+}
+
+// getResourceDetectors returns the enabled detectors, read from OTEL_RESOURCE_DETECTORS if they have not been set
+// (default: none).
+func getResourceDetectors() []string {
+	if configured := resourceDetectors.Load(); configured != nil {
+		return *configured
+	}
+
+	detectors, err := parseResourceDetectors(strings.Split(Getenv("OTEL_RESOURCE_DETECTORS"), ","))
+	if err != nil {
+		log.Printf("Failed to parse OTEL_RESOURCE_DETECTORS, detecting no attributes. %v", err)
+		return nil
+	}
+	return detectors
+}
+
+// detectorOptions returns the resource options of the detectors.
+func detectorOptions(detectors []string) []resource.Option {
+	var options []resource.Option
+	for _, detector := range detectors {
+		switch detector {
+		case DetectorHost:
+			options = append(options, resource.WithHost())
+		case DetectorProcess:
+			// The command line arguments are left out, as they may contain secrets
+			options = append(options,
+				resource.WithProcessPID(),
+				resource.WithProcessExecutableName(),
+				resource.WithProcessExecutablePath(),
+				resource.WithProcessOwner(),
+				resource.WithProcessRuntimeName(),
+				resource.WithProcessRuntimeVersion(),
+				resource.WithProcessRuntimeDescription(),
+			)
+		case DetectorContainer:
+			options = append(options, resource.WithContainer())
+		case DetectorKubernetes:
+			options = append(options, resource.WithDetectors(kubernetesDetector{}))
+		}
+	}
+	return options
+}
+
+// kubernetesDetector detects the pod attributes exposed via the downward API as environment variables (K8S_POD_NAME,
+// K8S_POD_UID, K8S_NAMESPACE_NAME and K8S_NODE_NAME). Outside of Kubernetes, no attributes are detected.
+type kubernetesDetector struct{}
+
+// Detect returns the pod attributes.
+func (kubernetesDetector) Detect(context.Context) (*resource.Resource, error) {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return resource.Empty(), nil
+	}
+
+	podName := Getenv("K8S_POD_NAME")
+	if podName == "" {
+		podName, _ = os.Hostname() // The host name of a pod is its name unless overridden in the spec
+	}
+	namespace := Getenv("K8S_NAMESPACE_NAME")
+	if namespace == "" {
+		if content, err := os.ReadFile(serviceAccountNamespaceFile); err == nil {
+			namespace = strings.TrimSpace(string(content))
+		}
+	}
+
+	var attributes []attribute.KeyValue
+	for key, value := range map[attribute.Key]string{
+		semconv.K8SPodNameKey:       podName,
+		semconv.K8SPodUIDKey:        Getenv("K8S_POD_UID"),
+		semconv.K8SNamespaceNameKey: namespace,
+		semconv.K8SNodeNameKey:      Getenv("K8S_NODE_NAME"),
+	} {
+		if value != "" {
+			attributes = append(attributes, key.String(value))
+		}
+	}
+
+	// Without a schema URL, the attributes can be merged with the ones of the built-in detectors
+	return resource.NewSchemaless(attributes...), nil
+}
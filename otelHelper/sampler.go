@@ -0,0 +1,317 @@
+package otelHelper
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Samplers of OTEL_TRACES_SAMPLER.
+const (
+	SamplerAlwaysOn                = "always_on"
+	SamplerAlwaysOff               = "always_off"
+	SamplerTraceIDRatio            = "traceidratio"
+	SamplerParentBasedAlwaysOn     = "parentbased_always_on"
+	SamplerParentBasedAlwaysOff    = "parentbased_always_off"
+	SamplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+// InvalidSamplerError is returned if an unsupported sampler or ratio is configured.
+var InvalidSamplerError = errors.New("Invalid sampler")
+
+// SamplerConfig selects the sampler of the tracer provider.
+type SamplerConfig struct {
+	Name  string  `json:"name"`  // One of the Sampler constants (default: SamplerParentBasedAlwaysOn)
+	Ratio float64 `json:"ratio"` // Fraction of the sampled traces of the trace ID ratio samplers (0 to 1)
+}
+
+var (
+	samplerConfig        atomic.Pointer[SamplerConfig]
+	defaultSamplerConfig atomic.Pointer[SamplerConfig]
+)
+
+// SetSampler selects the sampler of the tracer provider, which takes precedence over OTEL_TRACES_SAMPLER and
+// OTEL_TRACES_SAMPLER_ARG, so that high-traffic services can sample down. The noisy paths, the maximum sampling
+// ratio and ForceSample still apply. It has to be called before SetupOtelHelper.
+func SetSampler(config SamplerConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	samplerConfig.Store(&config)
+	warnSamplingLimit(config)
+	return nil
+}
+
+// SetDefaultSampler selects the sampler which is used if neither SetSampler nor OTEL_TRACES_SAMPLER select one (e.g.
+// the default sampler of an environment set by a policy). Nil restores SamplerParentBasedAlwaysOn. It has to be
+// called before SetupOtelHelper.
+func SetDefaultSampler(config *SamplerConfig) error {
+	if config == nil {
+		defaultSamplerConfig.Store(nil)
+		return nil
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	configCopy := *config
+	defaultSamplerConfig.Store(&configCopy)
+	return nil
+}
+
+// Validate checks the sampler name and ratio.
+func (config SamplerConfig) Validate() error {
+	switch config.Name {
+	case SamplerAlwaysOn, SamplerAlwaysOff, SamplerParentBasedAlwaysOn, SamplerParentBasedAlwaysOff:
+		return nil
+	case SamplerTraceIDRatio, SamplerParentBasedTraceIDRatio:
+		if config.Ratio < 0 || config.Ratio > 1 {
+			return errors.Wrapf(InvalidSamplerError, "Ratio %v is not between 0 and 1", config.Ratio)
+		}
+		return nil
+	default:
+		return errors.Wrapf(InvalidSamplerError, "Sampler %q", config.Name)
+	}
+}
+
+// ratio returns the fraction of the root spans which the sampler samples.
+func (config SamplerConfig) ratio() float64 {
+	switch config.Name {
+	case SamplerAlwaysOff, SamplerParentBasedAlwaysOff:
+		return 0
+	case SamplerTraceIDRatio, SamplerParentBasedTraceIDRatio:
+		return config.Ratio
+	default:
+		return 1
+	}
+}
+
+// getSamplerConfig returns the configured sampler, read from OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG if it
+// has not been set, or the default sampler (refer to SetDefaultSampler).
+func getSamplerConfig() SamplerConfig {
+	if configured := samplerConfig.Load(); configured != nil {
+		return *configured
+	}
+
+	config := SamplerConfig{Name: strings.ToLower(Getenv("OTEL_TRACES_SAMPLER")), Ratio: 1}
+	if config.Name == "" {
+		if defaultConfig := defaultSamplerConfig.Load(); defaultConfig != nil {
+			return *defaultConfig
+		}
+		config.Name = SamplerParentBasedAlwaysOn
+	}
+	if arg := Getenv("OTEL_TRACES_SAMPLER_ARG"); arg != "" {
+		ratio, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			log.Printf("Failed to parse OTEL_TRACES_SAMPLER_ARG, using 1. %v", err)
+		} else {
+			config.Ratio = ratio
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		log.Printf("Failed to apply OTEL_TRACES_SAMPLER, using %s. %v", SamplerParentBasedAlwaysOn, err)
+		return SamplerConfig{Name: SamplerParentBasedAlwaysOn}
+	}
+	return config
+}
+
+// newSampler returns the sampler of the tracer provider: the configured sampler with the reduced sampling of the root
+// spans of noisy paths, limited by SetMaxSamplingRatio and overridden by ForceSample.
+func newSampler(config SamplerConfig) trace.Sampler {
+	var root trace.Sampler
+	switch config.Name {
+	case SamplerAlwaysOff, SamplerParentBasedAlwaysOff:
+		root = trace.NeverSample()
+	case SamplerTraceIDRatio, SamplerParentBasedTraceIDRatio:
+		root = trace.TraceIDRatioBased(config.Ratio)
+	default:
+		root = trace.AlwaysSample()
+	}
+
+	sampler := newNoisePathSampler(root)
+	if strings.HasPrefix(config.Name, "parentbased_") {
+		sampler = trace.ParentBased(sampler)
+	}
+	return newForceSampler(newLimitedSampler(sampler))
+}
+
+// forceSampleKey is the context key of the flag set by ForceSample.
+type forceSampleKey struct{}
+
+// ForceSample returns a context which marks the spans started with it to be recorded and exported regardless of
+// the sampler decision (e.g. for admin-triggered diagnostics).
+func ForceSample(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceSampleKey{}, true)
+}
+
+// IsForceSampled reports whether the context has been marked by ForceSample.
+func IsForceSampled(ctx context.Context) bool {
+	forced, _ := ctx.Value(forceSampleKey{}).(bool)
+	return forced
+}
+
+// forceSampler is a sampler which samples all spans started with a context marked by ForceSample or belonging to a
+// trace with raised priority (refer to RaiseSamplingPriority) and delegates the decision for all other spans to the
+// wrapped sampler.
+type forceSampler struct {
+	sampler trace.Sampler
+}
+
+// newForceSampler wraps the sampler to cooperate with ForceSample.
+func newForceSampler(sampler trace.Sampler) trace.Sampler {
+	return forceSampler{sampler: sampler}
+}
+
+// ShouldSample returns the sampling decision for the span to be created.
+func (s forceSampler) ShouldSample(parameters trace.SamplingParameters) trace.SamplingResult {
+	forced := parameters.ParentContext != nil && IsForceSampled(parameters.ParentContext)
+	if forced || hasRaisedPriority(parameters.TraceID) {
+		result := s.sampler.ShouldSample(parameters) // Keep the attributes and trace state of the wrapped sampler
+		result.Decision = trace.RecordAndSample
+		return result
+	}
+
+	return s.sampler.ShouldSample(parameters)
+}
+
+// Description returns the description of the sampler.
+func (s forceSampler) Description() string {
+	return "ForceSampler{" + s.sampler.Description() + "}"
+}
+
+// maxSamplingRatio holds the maximum sampling ratio set by SetMaxSamplingRatio (nil if unlimited).
+var maxSamplingRatio atomic.Pointer[samplingLimit]
+
+// samplingLimit is a maximum sampling ratio with the sampler enforcing it.
+type samplingLimit struct {
+	ratio   float64
+	sampler trace.Sampler
+}
+
+// SetMaxSamplingRatio limits the fraction of traces which are sampled (e.g. by an organizational policy). Traces are
+// only sampled if both the configured sampler and the trace ID ratio of the limit sample them, so the decision is
+// consistent for all spans of a trace. A ratio of 1 or more removes the limit. Traces marked with ForceSample are
+// not limited. A warning is logged if the ratio of the configured sampler is clamped.
+func SetMaxSamplingRatio(ratio float64) {
+	if ratio >= 1 {
+		maxSamplingRatio.Store(nil)
+		return
+	}
+
+	maxSamplingRatio.Store(&samplingLimit{ratio: ratio, sampler: trace.TraceIDRatioBased(ratio)})
+	warnSamplingLimit(getSamplerConfig())
+}
+
+// warnSamplingLimit logs a warning if the ratio of the sampler exceeds the maximum sampling ratio, which clamps it.
+func warnSamplingLimit(config SamplerConfig) {
+	if limit := maxSamplingRatio.Load(); limit != nil && config.ratio() > limit.ratio {
+		log.Printf("Sampling ratio %v of the %s sampler exceeds the maximum sampling ratio %v, clamping it",
+			config.ratio(), config.Name, limit.ratio)
+	}
+}
+
+// limitedSampler is a sampler which enforces the maximum sampling ratio on the decisions of the wrapped sampler.
+type limitedSampler struct {
+	sampler trace.Sampler
+}
+
+// newLimitedSampler wraps the sampler to enforce the maximum sampling ratio.
+func newLimitedSampler(sampler trace.Sampler) trace.Sampler {
+	return limitedSampler{sampler: sampler}
+}
+
+// ShouldSample returns the sampling decision for the span to be created.
+func (s limitedSampler) ShouldSample(parameters trace.SamplingParameters) trace.SamplingResult {
+	result := s.sampler.ShouldSample(parameters)
+
+	limit := maxSamplingRatio.Load()
+	if limit != nil && result.Decision == trace.RecordAndSample &&
+		limit.sampler.ShouldSample(parameters).Decision != trace.RecordAndSample {
+		result.Decision = trace.Drop
+	}
+
+	return result
+}
+
+// Description returns the description of the sampler.
+func (s limitedSampler) Description() string {
+	return "LimitedSampler{" + s.sampler.Description() + "}"
+}
+
+// noisyPaths holds the configuration set by SetNoisyPaths (nil if not configured).
+var noisyPaths atomic.Pointer[noisyPathConfig]
+
+// noisyPathConfig holds the paths which are traced at a reduced sampling rate and the sampler for them.
+type noisyPathConfig struct {
+	paths   map[string]bool
+	sampler trace.Sampler
+}
+
+// pathAttributeKeys are the span attributes which contain the path of HTTP server spans (e.g. set by otelhttp).
+var pathAttributeKeys = []attribute.Key{"url.path", "http.target", "http.route"}
+
+// SetNoisyPaths configures paths (e.g. /healthz or /metrics), whose root spans are sampled at the given reduced
+// ratio, since they otherwise dominate the telemetry volume. The path is taken from the url.path, http.target or
+// http.route attribute at the start of the span, or from the span name (e.g. "GET /healthz").
+func SetNoisyPaths(ratio float64, paths ...string) {
+	if len(paths) == 0 {
+		noisyPaths.Store(nil)
+		return
+	}
+
+	config := &noisyPathConfig{paths: make(map[string]bool, len(paths)), sampler: trace.TraceIDRatioBased(ratio)}
+	for _, path := range paths {
+		config.paths[path] = true
+	}
+	noisyPaths.Store(config)
+}
+
+// noisePathSampler is a sampler which samples the spans of noisy paths at a reduced ratio and delegates the
+// decision for all other spans to the wrapped sampler.
+type noisePathSampler struct {
+	sampler trace.Sampler
+}
+
+// newNoisePathSampler wraps the sampler to reduce the sampling of noisy paths.
+func newNoisePathSampler(sampler trace.Sampler) trace.Sampler {
+	return noisePathSampler{sampler: sampler}
+}
+
+// ShouldSample returns the sampling decision for the span to be created.
+func (s noisePathSampler) ShouldSample(parameters trace.SamplingParameters) trace.SamplingResult {
+	if config := noisyPaths.Load(); config != nil && config.matches(parameters) {
+		return config.sampler.ShouldSample(parameters)
+	}
+
+	return s.sampler.ShouldSample(parameters)
+}
+
+// Description returns the description of the sampler.
+func (s noisePathSampler) Description() string {
+	return "NoisePathSampler{" + s.sampler.Description() + "}"
+}
+
+// matches reports whether the span to be created belongs to a noisy path.
+func (c *noisyPathConfig) matches(parameters trace.SamplingParameters) bool {
+	for _, attr := range parameters.Attributes {
+		for _, key := range pathAttributeKeys {
+			if attr.Key == key {
+				path, _, _ := strings.Cut(attr.Value.AsString(), "?")
+				if c.paths[path] {
+					return true
+				}
+			}
+		}
+	}
+
+	// Fall back to the span name, which often contains the method and the path
+	_, path, found := strings.Cut(parameters.Name, " ")
+	return c.paths[parameters.Name] || (found && c.paths[path])
+}
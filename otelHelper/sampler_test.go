@@ -0,0 +1,177 @@
+package otelHelper
+
+import (
+	"bytes"
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+// lowTraceID and highTraceID are trace IDs far below and far above the threshold of a trace ID ratio of 0.5.
+var (
+	lowTraceID  = trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+	highTraceID = trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}
+)
+
+// resetSamplers restores the global sampler configuration after the test.
+func resetSamplers(t *testing.T) {
+	t.Cleanup(func() {
+		SetNoisyPaths(0)
+		SetMaxSamplingRatio(1)
+		samplerConfig.Store(nil)
+		defaultSamplerConfig.Store(nil)
+	})
+}
+
+// rootParameters returns the sampling parameters of a root span.
+func rootParameters(ctx context.Context, traceID trace.TraceID, name string,
+	attributes ...attribute.KeyValue) sdktrace.SamplingParameters {
+	return sdktrace.SamplingParameters{ParentContext: ctx, TraceID: traceID, Name: name, Attributes: attributes}
+}
+
+// childContext returns a context with a remote parent span, which is sampled if requested.
+func childContext(traceID trace.TraceID, sampled bool) context.Context {
+	var flags trace.TraceFlags
+	if sampled {
+		flags = trace.FlagsSampled
+	}
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: trace.SpanID{1}, TraceFlags: flags, Remote: true,
+	})
+	return trace.ContextWithSpanContext(context.Background(), parent)
+}
+
+func TestSamplerNoisyPaths(t *testing.T) {
+	resetSamplers(t)
+	SetNoisyPaths(0, "/healthz")
+
+	sampler := newSampler(SamplerConfig{Name: SamplerParentBasedAlwaysOn})
+	ctx := context.Background()
+
+	if got := sampler.ShouldSample(rootParameters(ctx, lowTraceID, "GET /healthz")).Decision; got != sdktrace.Drop {
+		t.Errorf("Noisy path by span name sampled: %v", got)
+	}
+	got := sampler.ShouldSample(rootParameters(ctx, lowTraceID, "GET", attribute.String("url.path", "/healthz?probe=1")))
+	if got.Decision != sdktrace.Drop {
+		t.Errorf("Noisy path by attribute sampled: %v", got.Decision)
+	}
+	got = sampler.ShouldSample(rootParameters(ctx, lowTraceID, "GET /orders"))
+	if got.Decision != sdktrace.RecordAndSample {
+		t.Errorf("Regular path dropped: %v", got.Decision)
+	}
+
+	// The noisy paths only apply to root spans, children follow their parent
+	parameters := rootParameters(childContext(lowTraceID, true), lowTraceID, "GET /healthz")
+	if got := sampler.ShouldSample(parameters).Decision; got != sdktrace.RecordAndSample {
+		t.Errorf("Child of a sampled parent dropped: %v", got)
+	}
+}
+
+func TestSamplerParentBased(t *testing.T) {
+	resetSamplers(t)
+	sampler := newSampler(SamplerConfig{Name: SamplerParentBasedTraceIDRatio, Ratio: 1})
+
+	parameters := rootParameters(childContext(lowTraceID, false), lowTraceID, "child")
+	if got := sampler.ShouldSample(parameters).Decision; got != sdktrace.Drop {
+		t.Errorf("Child of an unsampled parent sampled: %v", got)
+	}
+}
+
+func TestSamplerMaxRatio(t *testing.T) {
+	resetSamplers(t)
+	SetMaxSamplingRatio(0.5)
+
+	sampler := newSampler(SamplerConfig{Name: SamplerAlwaysOn})
+	ctx := context.Background()
+
+	if got := sampler.ShouldSample(rootParameters(ctx, lowTraceID, "op")).Decision; got != sdktrace.RecordAndSample {
+		t.Errorf("Trace within the limit dropped: %v", got)
+	}
+	if got := sampler.ShouldSample(rootParameters(ctx, highTraceID, "op")).Decision; got != sdktrace.Drop {
+		t.Errorf("Trace beyond the limit sampled: %v", got)
+	}
+
+	// ForceSample overrides the limit
+	forced := rootParameters(ForceSample(ctx), highTraceID, "op")
+	if got := sampler.ShouldSample(forced).Decision; got != sdktrace.RecordAndSample {
+		t.Errorf("Forced trace dropped: %v", got)
+	}
+}
+
+func TestSamplerAlwaysOffForced(t *testing.T) {
+	resetSamplers(t)
+	sampler := newSampler(SamplerConfig{Name: SamplerAlwaysOff})
+
+	if got := sampler.ShouldSample(rootParameters(context.Background(), lowTraceID, "op")).Decision; got != sdktrace.Drop {
+		t.Errorf("Span sampled by the always_off sampler: %v", got)
+	}
+	forced := rootParameters(ForceSample(context.Background()), lowTraceID, "op")
+	if got := sampler.ShouldSample(forced).Decision; got != sdktrace.RecordAndSample {
+		t.Errorf("Forced span dropped by the always_off sampler: %v", got)
+	}
+}
+
+func TestSamplerDefault(t *testing.T) {
+	resetSamplers(t)
+	t.Setenv("OTEL_TRACES_SAMPLER", "")
+
+	if err := SetDefaultSampler(&SamplerConfig{Name: "unknown"}); err == nil {
+		t.Error("Invalid default sampler accepted")
+	}
+
+	defaultConfig := SamplerConfig{Name: SamplerParentBasedTraceIDRatio, Ratio: 0.1}
+	if err := SetDefaultSampler(&defaultConfig); err != nil {
+		t.Fatal(err)
+	}
+	if got := getSamplerConfig(); got != defaultConfig {
+		t.Errorf("Sampler %+v, want the default %+v", got, defaultConfig)
+	}
+
+	// The environment and SetSampler take precedence over the default
+	t.Setenv("OTEL_TRACES_SAMPLER", SamplerAlwaysOff)
+	if got := getSamplerConfig(); got.Name != SamplerAlwaysOff {
+		t.Errorf("Sampler %+v, want the one of the environment", got)
+	}
+	configured := SamplerConfig{Name: SamplerAlwaysOn}
+	if err := SetSampler(configured); err != nil {
+		t.Fatal(err)
+	}
+	if got := getSamplerConfig(); got != configured {
+		t.Errorf("Sampler %+v, want the configured %+v", got, configured)
+	}
+}
+
+func TestSamplerMaxRatioWarning(t *testing.T) {
+	resetSamplers(t)
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	if err := SetSampler(SamplerConfig{Name: SamplerTraceIDRatio, Ratio: 0.2}); err != nil {
+		t.Fatal(err)
+	}
+	SetMaxSamplingRatio(0.5)
+	if buf.Len() != 0 {
+		t.Errorf("Warning for a sampler within the limit: %q", buf.String())
+	}
+
+	if err := SetSampler(SamplerConfig{Name: SamplerTraceIDRatio, Ratio: 0.8}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "exceeds the maximum sampling ratio") {
+		t.Errorf("No warning for a clamped sampler: %q", buf.String())
+	}
+
+	// Lowering the limit below the configured sampler warns as well
+	buf.Reset()
+	SetMaxSamplingRatio(0.5)
+	if !strings.Contains(buf.String(), "exceeds the maximum sampling ratio") {
+		t.Errorf("No warning for a clamped sampler: %q", buf.String())
+	}
+}
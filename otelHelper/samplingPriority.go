@@ -0,0 +1,64 @@
+package otelHelper
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"time"
+)
+
+// SamplingPriorityKey is the attribute marking spans whose trace should be retained by tail-based samplers.
+const SamplingPriorityKey = "sampling.priority"
+
+// priorityTTL is the time for which spans of a trace with raised priority are sampled.
+const priorityTTL = time.Minute
+
+// maxPriorityTraces bounds the number of traces with raised priority which are remembered.
+const maxPriorityTraces = 10000
+
+var (
+	priorityMu     sync.Mutex
+	priorityTraces = make(map[trace.TraceID]time.Time) // Trace ID -> expiry of the raised priority
+)
+
+// RaiseSamplingPriority raises the sampling priority of the trace of the span from the context (e.g. because it
+// produced error logs): the span is marked with the sampling.priority attribute for tail-based samplers and the spans
+// of the trace started afterward in this process are sampled regardless of the sampler decision.
+func RaiseSamplingPriority(ctx context.Context) {
+	span := trace.SpanFromContext(ctx)
+	spanContext := span.SpanContext()
+	if !spanContext.HasTraceID() {
+		return
+	}
+	span.SetAttributes(attribute.Int(SamplingPriorityKey, 1))
+
+	priorityMu.Lock()
+	defer priorityMu.Unlock()
+
+	now := time.Now()
+	if len(priorityTraces) >= maxPriorityTraces {
+		for traceID, expiry := range priorityTraces {
+			if now.After(expiry) {
+				delete(priorityTraces, traceID)
+			}
+		}
+		if len(priorityTraces) >= maxPriorityTraces {
+			return // Keep the memory bounded under a flood of failing traces
+		}
+	}
+	priorityTraces[spanContext.TraceID()] = now.Add(priorityTTL)
+}
+
+// hasRaisedPriority reports whether the sampling priority of the trace has been raised recently.
+func hasRaisedPriority(traceID trace.TraceID) bool {
+	priorityMu.Lock()
+	defer priorityMu.Unlock()
+
+	expiry, ok := priorityTraces[traceID]
+	if ok && time.Now().After(expiry) {
+		delete(priorityTraces, traceID)
+		return false
+	}
+	return ok
+}
@@ -0,0 +1,165 @@
+package otelHelper
+
+import (
+	"context"
+	stderrors "errors"
+	"github.com/pkg/errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultShutdownBudget is the default time a component may take to shut down, so that a fatal entry terminates the
+// program even if the collector is unreachable.
+const DefaultShutdownBudget = 5 * time.Second
+
+// DefaultShutdownTimeout is the default time the entire shutdown may take if the context has no deadline.
+const DefaultShutdownTimeout = 10 * time.Second
+
+// ShutdownBudgetExceededError is reported if a component did not shut down within its budget.
+var ShutdownBudgetExceededError = errors.New("Shutdown budget exceeded")
+
+// shutdownComponent is a component (e.g. the tracer provider or a log sink) which is shut down by Shutdown.
+type shutdownComponent struct {
+	name     string
+	budget   time.Duration
+	shutdown func(ctx context.Context) error
+}
+
+var (
+	shutdownMu         sync.Mutex
+	shutdownComponents []*shutdownComponent
+	shutdownObservers  []func()
+
+	shutdownTimeout atomic.Int64 // Zero for DefaultShutdownTimeout
+	shutdownOnce    sync.Once
+	shutdownErr     error
+)
+
+// OnShutdown registers an observer which is called at the start of Shutdown, while the components are still running
+// (e.g. to log a final report).
+func OnShutdown(observer func()) {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+
+	shutdownObservers = append(shutdownObservers, observer)
+}
+
+// RegisterShutdown registers a component which is shut down by Shutdown within its own budget (DefaultShutdownBudget
+// if the budget is zero or less), e.g. a log sink which has to be flushed. Registering a name again replaces the
+// component.
+func RegisterShutdown(name string, budget time.Duration, shutdown func(ctx context.Context) error) {
+	if budget <= 0 {
+		budget = DefaultShutdownBudget
+	}
+
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+
+	for i, component := range shutdownComponents {
+		if component.name == name {
+			shutdownComponents[i] = &shutdownComponent{name: name, budget: budget, shutdown: shutdown}
+			return
+		}
+	}
+	shutdownComponents = append(shutdownComponents, &shutdownComponent{name: name, budget: budget, shutdown: shutdown})
+}
+
+// SetShutdownBudget updates the budget of a registered component, e.g. of the "tracer provider".
+func SetShutdownBudget(name string, budget time.Duration) {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+
+	for i, component := range shutdownComponents {
+		if component.name == name {
+			shutdownComponents[i] = &shutdownComponent{name: name, budget: budget, shutdown: component.shutdown}
+		}
+	}
+}
+
+// SetShutdownTimeout sets the time the entire shutdown may take if the context passed to ShutdownContext has no
+// deadline (default: DefaultShutdownTimeout).
+func SetShutdownTimeout(timeout time.Duration) {
+	shutdownTimeout.Store(int64(timeout))
+}
+
+// Shutdown exports the pending telemetry and shuts down the registered components like ShutdownContext and logs the
+// errors.
+func Shutdown() {
+	if err := ShutdownContext(context.Background()); err != nil {
+		log.Printf("Failed to shut down. %v", err)
+	}
+}
+
+// ShutdownContext exports the pending telemetry and shuts down the registered components concurrently, each within
+// its own budget and the deadline of the context (or the shutdown timeout if it has none). Components exceeding their
+// budget are abandoned, so that one stuck exporter cannot consume the entire termination grace period. The errors of
+// all components are returned. Only the first call shuts down, further calls wait for it and return its result, so
+// that it can be wired into the graceful shutdown of an HTTP server as well as deferred.
+func ShutdownContext(ctx context.Context) error {
+	shutdownOnce.Do(func() {
+		if _, ok := ctx.Deadline(); !ok {
+			timeout := time.Duration(shutdownTimeout.Load())
+			if timeout <= 0 {
+				timeout = DefaultShutdownTimeout
+			}
+
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		shutdownErr = shutdown(ctx)
+	})
+	return shutdownErr
+}
+
+// shutdown notifies the observers and shuts down the registered components concurrently.
+func shutdown(ctx context.Context) error {
+	shutdownMu.Lock()
+	components := append([]*shutdownComponent(nil), shutdownComponents...)
+	observers := append([]func(){}, shutdownObservers...)
+	shutdownMu.Unlock()
+
+	for _, observer := range observers {
+		observer()
+	}
+
+	errs := make([]error, len(components))
+	var wg sync.WaitGroup
+	for i, component := range components {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := component.run(ctx); err != nil {
+				errs[i] = errors.Wrapf(err, "Failed to shut down the %s", component.name)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return stderrors.Join(errs...)
+}
+
+// run shuts down the component and returns ShutdownBudgetExceededError if it does not return within its budget or
+// the error of the parent context if it is done before.
+func (component *shutdownComponent) run(parent context.Context) error {
+	ctx, cancel := context.WithTimeout(parent, component.budget)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- component.shutdown(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if err := parent.Err(); err != nil {
+			return errors.Wrapf(err, "%s did not shut down before the deadline", component.name)
+		}
+		return errors.Wrapf(ShutdownBudgetExceededError, "%s did not shut down within %s", component.name, component.budget)
+	}
+}
@@ -0,0 +1,114 @@
+package otelHelper
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// defaultTracerName is the name of the tracer of StartSpan until the service name is known.
+const defaultTracerName = "github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+
+var tracerName atomic.Pointer[string]
+
+// StartSpan starts a span with the tracer named after the service (OTEL_SERVICE_NAME) and adds the calling function,
+// file and line as code attributes, so that application code does not have to create tracers. The span is internal
+// unless a kind option (e.g. AsServer) is given. The span has to be ended and the returned context used for the log
+// entries of the operation:
+//
+//	ctx, span := otelHelper.StartSpan(ctx, "GET /orders/{id}", otelHelper.AsServer(),
+//		otelHelper.HTTPServerAttributes(r.Method, "/orders/{id}"))
+//	defer span.End()
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	opts = append([]trace.SpanStartOption{trace.WithSpanKind(trace.SpanKindInternal)}, opts...)
+	if pc, file, line, ok := runtime.Caller(1); ok {
+		function := runtime.FuncForPC(pc).Name()
+		namespace := ""
+		if i := strings.LastIndex(function, "."); i > strings.LastIndex(function, "/") {
+			namespace, function = function[:i], function[i+1:]
+		}
+		opts = append([]trace.SpanStartOption{trace.WithAttributes(
+			semconv.CodeFunction(function),
+			semconv.CodeNamespace(namespace),
+			semconv.CodeFilepath(file),
+			semconv.CodeLineNumber(line),
+		)}, opts...)
+	}
+
+	return otel.Tracer(getTracerName()).Start(ctx, name, opts...)
+}
+
+// getTracerName returns the name of the tracer of StartSpan.
+func getTracerName() string {
+	if name := tracerName.Load(); name != nil {
+		return *name
+	}
+	return defaultTracerName
+}
+
+// AsServer marks the span started with StartSpan as handling a synchronous request of a remote client.
+func AsServer() trace.SpanStartOption {
+	return trace.WithSpanKind(trace.SpanKindServer)
+}
+
+// AsClient marks the span started with StartSpan as synchronous request to a remote service.
+func AsClient() trace.SpanStartOption {
+	return trace.WithSpanKind(trace.SpanKindClient)
+}
+
+// AsProducer marks the span started with StartSpan as sending a message, which is processed asynchronously.
+func AsProducer() trace.SpanStartOption {
+	return trace.WithSpanKind(trace.SpanKindProducer)
+}
+
+// AsConsumer marks the span started with StartSpan as processing a message sent by a producer.
+func AsConsumer() trace.SpanStartOption {
+	return trace.WithSpanKind(trace.SpanKindConsumer)
+}
+
+// HTTPServerAttributes returns the semantic convention attributes of an incoming HTTP request, e.g. "GET" and the
+// route template "/orders/{id}". Empty values are left out.
+func HTTPServerAttributes(method, route string) trace.SpanStartOption {
+	return withAttributes(semconv.HTTPRequestMethodKey.String(method), semconv.HTTPRoute(route))
+}
+
+// HTTPClientAttributes returns the semantic convention attributes of an outgoing HTTP request with the full URL.
+// Empty values are left out.
+func HTTPClientAttributes(method, url string) trace.SpanStartOption {
+	return withAttributes(semconv.HTTPRequestMethodKey.String(method), semconv.URLFull(url))
+}
+
+// DBAttributes returns the semantic convention attributes of a database call, e.g. "postgresql", "SELECT" and
+// "orders". Empty values are left out.
+func DBAttributes(system, operation, table string) trace.SpanStartOption {
+	return withAttributes(semconv.DBSystemKey.String(system), semconv.DBOperation(operation), semconv.DBSQLTable(table))
+}
+
+// MessagingAttributes returns the semantic convention attributes of a messaging operation, e.g. "kafka", the topic
+// and "publish". Empty values are left out.
+func MessagingAttributes(system, destination, operation string) trace.SpanStartOption {
+	return withAttributes(semconv.MessagingSystemKey.String(system), semconv.MessagingDestinationName(destination),
+		semconv.MessagingOperationKey.String(operation))
+}
+
+// RPCAttributes returns the semantic convention attributes of a remote procedure call, e.g. "grpc", the service and
+// the method. Empty values are left out.
+func RPCAttributes(system, service, method string) trace.SpanStartOption {
+	return withAttributes(semconv.RPCSystemKey.String(system), semconv.RPCService(service), semconv.RPCMethod(method))
+}
+
+// withAttributes returns a start option with the attributes whose values are not empty.
+func withAttributes(attributes ...attribute.KeyValue) trace.SpanStartOption {
+	set := make([]attribute.KeyValue, 0, len(attributes))
+	for _, kv := range attributes {
+		if kv.Value.AsString() != "" {
+			set = append(set, kv)
+		}
+	}
+	return trace.WithAttributes(set...)
+}
@@ -0,0 +1,82 @@
+package otelHelper
+
+import (
+	"go.opentelemetry.io/otel/sdk/trace"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of the span export (the defaults of the batch span processor of the specification).
+const (
+	DefaultSpanMaxQueueSize  = 2048
+	DefaultSpanBatchTimeout  = 5 * time.Second
+	DefaultSpanMaxBatchSize  = 512
+	DefaultSpanExportTimeout = 30 * time.Second
+)
+
+// SpanExportConfig configures the batch span processors of the exporters and the retries of the OTLP exporter. Zero
+// values are read from the OTEL_BSP_ variables of the specification or set to the defaults.
+type SpanExportConfig struct {
+	MaxQueueSize  int           // Spans waiting for the export, further spans are dropped (OTEL_BSP_MAX_QUEUE_SIZE)
+	BatchTimeout  time.Duration // Delay after which an incomplete batch is exported (OTEL_BSP_SCHEDULE_DELAY in milliseconds)
+	MaxBatchSize  int           // Spans per export (OTEL_BSP_MAX_EXPORT_BATCH_SIZE)
+	ExportTimeout time.Duration // Timeout of an export including its retries (OTEL_BSP_EXPORT_TIMEOUT in milliseconds)
+
+	RetryDisabled        bool          // Drop the spans of a failed export instead of retrying it
+	RetryInitialInterval time.Duration // Delay before the first retry, increased exponentially (default: 5s)
+	RetryMaxInterval     time.Duration // Upper bound of the delay between retries (default: 30s)
+	RetryMaxElapsedTime  time.Duration // Time after which a failed export is given up (default: 1m)
+}
+
+var spanExportConfig atomic.Pointer[SpanExportConfig]
+
+// SetSpanExportConfig configures the export of the spans, e.g. a larger queue for high-throughput services. It has
+// to be called before SetupOtelHelper.
+func SetSpanExportConfig(config SpanExportConfig) {
+	spanExportConfig.Store(&config)
+}
+
+// getSpanExportConfig returns the configured span export config with the unset values resolved.
+func getSpanExportConfig() SpanExportConfig {
+	var config SpanExportConfig
+	if configured := spanExportConfig.Load(); configured != nil {
+		config = *configured
+	}
+
+	if config.MaxQueueSize <= 0 {
+		config.MaxQueueSize = envInt("OTEL_BSP_MAX_QUEUE_SIZE", DefaultSpanMaxQueueSize)
+	}
+	if config.BatchTimeout <= 0 {
+		config.BatchTimeout = time.Duration(envInt("OTEL_BSP_SCHEDULE_DELAY",
+			int(DefaultSpanBatchTimeout.Milliseconds()))) * time.Millisecond
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = envInt("OTEL_BSP_MAX_EXPORT_BATCH_SIZE", DefaultSpanMaxBatchSize)
+	}
+	config.MaxBatchSize = min(config.MaxBatchSize, config.MaxQueueSize)
+	if config.ExportTimeout <= 0 {
+		config.ExportTimeout = time.Duration(envInt("OTEL_BSP_EXPORT_TIMEOUT",
+			int(DefaultSpanExportTimeout.Milliseconds()))) * time.Millisecond
+	}
+	if config.RetryInitialInterval <= 0 {
+		config.RetryInitialInterval = 5 * time.Second
+	}
+	if config.RetryMaxInterval <= 0 {
+		config.RetryMaxInterval = 30 * time.Second
+	}
+	if config.RetryMaxElapsedTime <= 0 {
+		config.RetryMaxElapsedTime = time.Minute
+	}
+
+	return config
+}
+
+// batchOptions returns the options of the batch span processors.
+func (config SpanExportConfig) batchOptions() []trace.BatchSpanProcessorOption {
+	return []trace.BatchSpanProcessorOption{
+		trace.WithMaxQueueSize(config.MaxQueueSize),
+		trace.WithBatchTimeout(config.BatchTimeout),
+		trace.WithMaxExportBatchSize(config.MaxBatchSize),
+		trace.WithExportTimeout(config.ExportTimeout),
+	}
+}
@@ -0,0 +1,63 @@
+package otelHelper
+
+import (
+	"go.opentelemetry.io/otel/sdk/trace"
+	"sync/atomic"
+)
+
+// SpanLimitsConfig bounds the size of the spans, e.g. of services whose logging hook adds an event for every warning.
+// Zero values are read from the OTEL_SPAN_ variables of the specification or set to the defaults of the SDK, negative
+// values disable the limit.
+type SpanLimitsConfig struct {
+	MaxAttributes           int // Attributes per span (OTEL_SPAN_ATTRIBUTE_COUNT_LIMIT, default: 128)
+	MaxEvents               int // Events per span, further events are dropped (OTEL_SPAN_EVENT_COUNT_LIMIT, default: 128)
+	MaxLinks                int // Links per span (OTEL_SPAN_LINK_COUNT_LIMIT, default: 128)
+	MaxAttributesPerEvent   int // Attributes per event (OTEL_EVENT_ATTRIBUTE_COUNT_LIMIT, default: 128)
+	MaxAttributesPerLink    int // Attributes per link (OTEL_LINK_ATTRIBUTE_COUNT_LIMIT, default: 128)
+	MaxAttributeValueLength int // Characters of string attributes (OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT, default: unlimited)
+}
+
+var spanLimitsConfig atomic.Pointer[SpanLimitsConfig]
+
+// SetSpanLimits configures the limits of the spans. It has to be called before SetupOtelHelper.
+func SetSpanLimits(config SpanLimitsConfig) {
+	spanLimitsConfig.Store(&config)
+}
+
+// getSpanLimits returns the configured span limits with the unset values resolved.
+func getSpanLimits() trace.SpanLimits {
+	var config SpanLimitsConfig
+	if configured := spanLimitsConfig.Load(); configured != nil {
+		config = *configured
+	}
+
+	if config.MaxAttributes == 0 {
+		config.MaxAttributes = envInt("OTEL_SPAN_ATTRIBUTE_COUNT_LIMIT", trace.DefaultAttributeCountLimit)
+	}
+	if config.MaxEvents == 0 {
+		config.MaxEvents = envInt("OTEL_SPAN_EVENT_COUNT_LIMIT", trace.DefaultEventCountLimit)
+	}
+	if config.MaxLinks == 0 {
+		config.MaxLinks = envInt("OTEL_SPAN_LINK_COUNT_LIMIT", trace.DefaultLinkCountLimit)
+	}
+	if config.MaxAttributesPerEvent == 0 {
+		config.MaxAttributesPerEvent = envInt("OTEL_EVENT_ATTRIBUTE_COUNT_LIMIT",
+			trace.DefaultAttributePerEventCountLimit)
+	}
+	if config.MaxAttributesPerLink == 0 {
+		config.MaxAttributesPerLink = envInt("OTEL_LINK_ATTRIBUTE_COUNT_LIMIT", trace.DefaultAttributePerLinkCountLimit)
+	}
+	if config.MaxAttributeValueLength == 0 {
+		config.MaxAttributeValueLength = envInt("OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT",
+			envInt("OTEL_ATTRIBUTE_VALUE_LENGTH_LIMIT", trace.DefaultAttributeValueLengthLimit))
+	}
+
+	return trace.SpanLimits{
+		AttributeCountLimit:         config.MaxAttributes,
+		EventCountLimit:             config.MaxEvents,
+		LinkCountLimit:              config.MaxLinks,
+		AttributePerEventCountLimit: config.MaxAttributesPerEvent,
+		AttributePerLinkCountLimit:  config.MaxAttributesPerLink,
+		AttributeValueLengthLimit:   config.MaxAttributeValueLength,
+	}
+}
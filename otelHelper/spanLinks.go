@@ -0,0 +1,51 @@
+package otelHelper
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InvalidSpanContextError is returned if a serialized span context cannot be restored.
+var InvalidSpanContextError = errors.New("Invalid serialized span context")
+
+// traceparentHeader is the key of the W3C trace context header used as serialization format.
+const traceparentHeader = "traceparent"
+
+// SerializeSpanContext returns the span context of the active span as a compact string (W3C traceparent format),
+// which can be stored alongside queued jobs or database rows. An empty string is returned if there is no valid span.
+func SerializeSpanContext(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	return carrier.Get(traceparentHeader)
+}
+
+// DeserializeSpanContext restores a span context serialized with SerializeSpanContext.
+func DeserializeSpanContext(serialized string) (trace.SpanContext, error) {
+	carrier := propagation.MapCarrier{traceparentHeader: serialized}
+	ctx := propagation.TraceContext{}.Extract(context.Background(), carrier)
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return trace.SpanContext{}, errors.Wrapf(InvalidSpanContextError, "Failed to parse %q", serialized)
+	}
+
+	return spanContext, nil
+}
+
+// LinkFromString restores a span context serialized with SerializeSpanContext as a span link, so that delayed
+// processing still connects to the originating trace:
+//
+//	link, err := otelHelper.LinkFromString(job.TraceContext)
+//	ctx, span := tracer.Start(ctx, "ProcessJob", trace.WithLinks(link))
+func LinkFromString(serialized string, attributes ...attribute.KeyValue) (trace.Link, error) {
+	spanContext, err := DeserializeSpanContext(serialized)
+	if err != nil {
+		return trace.Link{}, err
+	}
+
+	return trace.Link{SpanContext: spanContext, Attributes: attributes}, nil
+}
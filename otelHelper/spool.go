@@ -0,0 +1,255 @@
+package otelHelper
+
+import (
+	"bufio"
+	"context"
+	"github.com/pkg/errors"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Defaults of the spool.
+const (
+	DefaultSpoolMaxBytes = 64 * 1024 * 1024
+	spoolReplayBatchSize = 512
+)
+
+// SpoolConfig configures the disk-backed buffer of the telemetry that could not be exported. Zero values are read
+// from OTEL_SPOOL_DIR and OTEL_SPOOL_MAX_BYTES.
+type SpoolConfig struct {
+	Dir      string // Directory of the spool files, the spool is disabled if empty
+	MaxBytes int64  // Size limit per signal, further records are dropped (default: DefaultSpoolMaxBytes)
+}
+
+var spoolConfig atomic.Pointer[SpoolConfig]
+
+// SetSpoolConfig enables the spool: spans and log records whose export failed after all retries (e.g. during a
+// collector rollout) are written to a bounded buffer on disk and replayed after the next successful export, also
+// by the next process using the same directory. It has to be called before SetupOtelHelper.
+func SetSpoolConfig(config SpoolConfig) {
+	spoolConfig.Store(&config)
+}
+
+// getSpoolConfig returns the configured spool config with the unset values resolved.
+func getSpoolConfig() SpoolConfig {
+	var config SpoolConfig
+	if configured := spoolConfig.Load(); configured != nil {
+		config = *configured
+	}
+
+	if config.Dir == "" {
+		config.Dir = Getenv("OTEL_SPOOL_DIR")
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = int64(DefaultSpoolMaxBytes)
+		if value := Getenv("OTEL_SPOOL_MAX_BYTES"); value != "" {
+			maxBytes, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || maxBytes <= 0 {
+				log.Printf("Failed to parse OTEL_SPOOL_MAX_BYTES, using default. %v", err)
+			} else {
+				config.MaxBytes = maxBytes
+			}
+		}
+	}
+
+	return config
+}
+
+var (
+	spooledRecords  atomic.Int64
+	replayedRecords atomic.Int64
+	spoolDropped    atomic.Int64
+)
+
+// SpoolStats summarizes the spooled spans and log records of the process lifetime.
+type SpoolStats struct {
+	Spooled  int64 // Records written to the spool
+	Replayed int64 // Records exported from the spool
+	Dropped  int64 // Records dropped because the spool was full or corrupted
+}
+
+// GetSpoolStats returns the spool statistics.
+func GetSpoolStats() SpoolStats {
+	return SpoolStats{Spooled: spooledRecords.Load(), Replayed: replayedRecords.Load(), Dropped: spoolDropped.Load()}
+}
+
+// spool is a bounded file of encoded records (one per line) of a signal waiting to be replayed.
+type spool struct {
+	path     string
+	maxBytes int64
+
+	mu        sync.Mutex
+	size      atomic.Int64 // Size of the spool file, zero if there is nothing to replay
+	replaying atomic.Bool
+}
+
+// newSpool creates the spool of the signal in the directory of the config, keeping the records of previous runs.
+func newSpool(config SpoolConfig, signal string) (*spool, error) {
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "Failed to create the spool directory")
+	}
+
+	s := &spool{path: filepath.Join(config.Dir, signal+".jsonl"), maxBytes: config.MaxBytes}
+	if info, err := os.Stat(s.path); err == nil {
+		s.size.Store(info.Size())
+	}
+	if _, err := os.Stat(s.replayPath()); err == nil {
+		s.size.Add(1) // Replay the records of an interrupted replay
+	}
+
+	return s, nil
+}
+
+// newSignalSpool returns the spool of the signal or nil if the spool is disabled or cannot be created.
+func newSignalSpool(signal string) *spool {
+	config := getSpoolConfig()
+	if config.Dir == "" {
+		return nil
+	}
+
+	s, err := newSpool(config, signal)
+	if err != nil {
+		log.Printf("Failed to set up the spool of the %s, failed exports will be lost. %v", signal, err)
+		return nil
+	}
+	return s
+}
+
+// replayPath returns the path of the file the records are moved to while they are replayed.
+func (s *spool) replayPath() string {
+	return s.path + ".replay"
+}
+
+// write appends the records to the spool and drops the records exceeding its size limit.
+func (s *spool) write(records [][]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		log.Printf("Failed to open the spool %s. %v", s.path, err)
+		spoolDropped.Add(int64(len(records)))
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		log.Printf("Failed to read the size of the spool %s. %v", s.path, err)
+		spoolDropped.Add(int64(len(records)))
+		return
+	}
+	size := info.Size()
+
+	writer := bufio.NewWriter(file)
+	for i, record := range records {
+		if size+int64(len(record))+1 > s.maxBytes {
+			spoolDropped.Add(int64(len(records) - i))
+			break
+		}
+		_, _ = writer.Write(record)
+		_ = writer.WriteByte('\n')
+		size += int64(len(record)) + 1
+		spooledRecords.Add(1)
+	}
+	if err := writer.Flush(); err != nil {
+		log.Printf("Failed to write to the spool %s. %v", s.path, err)
+	}
+	s.size.Store(size)
+}
+
+// replay sends the spooled records in batches unless there are none or a replay is already running. Records that
+// could not be sent are written back to the spool.
+func (s *spool) replay(ctx context.Context, send func(ctx context.Context, records [][]byte) error) {
+	if s.size.Load() == 0 || !s.replaying.CompareAndSwap(false, true) {
+		return
+	}
+	defer s.replaying.Store(false)
+
+	// Move the records aside, so that failed exports during the replay can be spooled again
+	s.mu.Lock()
+	if _, err := os.Stat(s.replayPath()); os.IsNotExist(err) {
+		if err := os.Rename(s.path, s.replayPath()); err != nil && !os.IsNotExist(err) {
+			s.mu.Unlock()
+			log.Printf("Failed to move the spool %s. %v", s.path, err)
+			return
+		}
+	}
+	s.size.Store(0)
+	s.mu.Unlock()
+
+	file, err := os.Open(s.replayPath())
+	if err != nil {
+		log.Printf("Failed to open the spool %s. %v", s.replayPath(), err)
+		return
+	}
+
+	var records [][]byte
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), int(s.maxBytes))
+	for scanner.Scan() {
+		records = append(records, append([]byte(nil), scanner.Bytes()...))
+	}
+	_ = file.Close()
+
+	for start := 0; start < len(records); start += spoolReplayBatchSize {
+		batch := records[start:min(start+spoolReplayBatchSize, len(records))]
+		if err := send(ctx, batch); err != nil {
+			s.write(records[start:]) // Keep the remaining records for the next replay
+			break
+		}
+		replayedRecords.Add(int64(len(batch)))
+	}
+
+	if err := os.Remove(s.replayPath()); err != nil {
+		log.Printf("Failed to remove the spool %s. %v", s.replayPath(), err)
+	}
+}
+
+// spoolingSpanExporter is a span exporter which spools the spans of failed exports and replays them after the next
+// successful export.
+type spoolingSpanExporter struct {
+	trace.SpanExporter
+	spool *spool
+}
+
+// ExportSpans exports the spans with the wrapped exporter and spools them if the export failed.
+func (e spoolingSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	if err != nil {
+		e.spool.write(encodeSpans(spans))
+		return err
+	}
+
+	go e.spool.replay(context.Background(), func(ctx context.Context, records [][]byte) error {
+		return e.SpanExporter.ExportSpans(ctx, decodeSpans(records))
+	})
+	return nil
+}
+
+// spoolingLogExporter is a log exporter which spools the records of failed exports and replays them after the next
+// successful export.
+type spoolingLogExporter struct {
+	sdklog.Exporter
+	spool *spool
+}
+
+// Export exports the records with the wrapped exporter and spools them if the export failed.
+func (e spoolingLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	err := e.Exporter.Export(ctx, records)
+	if err != nil {
+		e.spool.write(encodeLogRecords(records))
+		return err
+	}
+
+	go e.spool.replay(context.Background(), func(ctx context.Context, records [][]byte) error {
+		return e.Exporter.Export(ctx, decodeLogRecords(records))
+	})
+	return nil
+}
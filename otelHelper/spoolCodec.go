@@ -0,0 +1,433 @@
+package otelHelper
+
+import (
+	"context"
+	"encoding/json"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// spoolKeyValue is an attribute in the spool with its type, so that it is restored with the same type.
+type spoolKeyValue struct {
+	Key   string          `json:"key"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// spoolSpanContext is a span context in the spool.
+type spoolSpanContext struct {
+	TraceID    string `json:"trace_id"`
+	SpanID     string `json:"span_id"`
+	TraceFlags byte   `json:"trace_flags"`
+	TraceState string `json:"trace_state,omitempty"`
+	Remote     bool   `json:"remote,omitempty"`
+}
+
+// spoolEvent is a span event in the spool.
+type spoolEvent struct {
+	Name       string          `json:"name"`
+	Time       time.Time       `json:"time"`
+	Attributes []spoolKeyValue `json:"attributes,omitempty"`
+}
+
+// spoolLink is a span link in the spool.
+type spoolLink struct {
+	SpanContext spoolSpanContext `json:"span_context"`
+	Attributes  []spoolKeyValue  `json:"attributes,omitempty"`
+}
+
+// spoolScope is the resource and instrumentation scope of a record in the spool.
+type spoolScope struct {
+	Resource       []spoolKeyValue `json:"resource,omitempty"`
+	SchemaURL      string          `json:"schema_url,omitempty"`
+	ScopeName      string          `json:"scope_name"`
+	ScopeVersion   string          `json:"scope_version,omitempty"`
+	ScopeSchemaURL string          `json:"scope_schema_url,omitempty"`
+}
+
+// spoolSpan is a span in the spool.
+type spoolSpan struct {
+	spoolScope
+	Name              string           `json:"name"`
+	SpanContext       spoolSpanContext `json:"span_context"`
+	Parent            spoolSpanContext `json:"parent"`
+	Kind              int              `json:"kind"`
+	Start             time.Time        `json:"start"`
+	End               time.Time        `json:"end"`
+	Attributes        []spoolKeyValue  `json:"attributes,omitempty"`
+	Events            []spoolEvent     `json:"events,omitempty"`
+	Links             []spoolLink      `json:"links,omitempty"`
+	StatusCode        uint32           `json:"status_code"`
+	StatusDescription string           `json:"status_description,omitempty"`
+}
+
+// spoolLogRecord is a log record in the spool.
+type spoolLogRecord struct {
+	spoolScope
+	Timestamp         time.Time       `json:"timestamp"`
+	ObservedTimestamp time.Time       `json:"observed_timestamp"`
+	Severity          int             `json:"severity"`
+	SeverityText      string          `json:"severity_text,omitempty"`
+	Body              spoolKeyValue   `json:"body"`
+	Attributes        []spoolKeyValue `json:"attributes,omitempty"`
+	TraceID           string          `json:"trace_id,omitempty"`
+	SpanID            string          `json:"span_id,omitempty"`
+	TraceFlags        byte            `json:"trace_flags,omitempty"`
+}
+
+// encodeSpans encodes the spans as spool records.
+func encodeSpans(spans []trace.ReadOnlySpan) [][]byte {
+	records := make([][]byte, 0, len(spans))
+	for _, span := range spans {
+		encoded := spoolSpan{
+			spoolScope:        encodeScope(span.Resource(), span.InstrumentationScope()),
+			Name:              span.Name(),
+			SpanContext:       encodeSpanContext(span.SpanContext()),
+			Parent:            encodeSpanContext(span.Parent()),
+			Kind:              int(span.SpanKind()),
+			Start:             span.StartTime(),
+			End:               span.EndTime(),
+			Attributes:        encodeAttributes(span.Attributes()),
+			StatusCode:        uint32(span.Status().Code),
+			StatusDescription: span.Status().Description,
+		}
+		for _, event := range span.Events() {
+			encoded.Events = append(encoded.Events, spoolEvent{
+				Name:       event.Name,
+				Time:       event.Time,
+				Attributes: encodeAttributes(event.Attributes),
+			})
+		}
+		for _, link := range span.Links() {
+			encoded.Links = append(encoded.Links, spoolLink{
+				SpanContext: encodeSpanContext(link.SpanContext),
+				Attributes:  encodeAttributes(link.Attributes),
+			})
+		}
+
+		if record, err := json.Marshal(encoded); err == nil {
+			records = append(records, record)
+		} else {
+			spoolDropped.Add(1)
+		}
+	}
+	return records
+}
+
+// decodeSpans decodes the spool records into spans, dropping corrupted records.
+func decodeSpans(records [][]byte) []trace.ReadOnlySpan {
+	spans := make([]trace.ReadOnlySpan, 0, len(records))
+	for _, record := range records {
+		var encoded spoolSpan
+		if err := json.Unmarshal(record, &encoded); err != nil {
+			spoolDropped.Add(1)
+			continue
+		}
+
+		res, scope := encoded.decodeScope()
+		stub := tracetest.SpanStub{
+			Name:        encoded.Name,
+			SpanContext: encoded.SpanContext.decode(),
+			Parent:      encoded.Parent.decode(),
+			SpanKind:    oteltrace.SpanKind(encoded.Kind),
+			StartTime:   encoded.Start,
+			EndTime:     encoded.End,
+			Attributes:  decodeAttributes(encoded.Attributes),
+			Status: trace.Status{
+				Code:        codes.Code(encoded.StatusCode),
+				Description: encoded.StatusDescription,
+			},
+			Resource:             res,
+			InstrumentationScope: scope,
+		}
+		for _, event := range encoded.Events {
+			stub.Events = append(stub.Events, trace.Event{
+				Name:       event.Name,
+				Time:       event.Time,
+				Attributes: decodeAttributes(event.Attributes),
+			})
+		}
+		for _, link := range encoded.Links {
+			stub.Links = append(stub.Links, trace.Link{
+				SpanContext: link.SpanContext.decode(),
+				Attributes:  decodeAttributes(link.Attributes),
+			})
+		}
+
+		spans = append(spans, stub.Snapshot())
+	}
+	return spans
+}
+
+// encodeLogRecords encodes the log records as spool records.
+func encodeLogRecords(logRecords []sdklog.Record) [][]byte {
+	records := make([][]byte, 0, len(logRecords))
+	for i := range logRecords {
+		logRecord := &logRecords[i]
+
+		res := logRecord.Resource()
+		encoded := spoolLogRecord{
+			spoolScope:        encodeScope(&res, logRecord.InstrumentationScope()),
+			Timestamp:         logRecord.Timestamp(),
+			ObservedTimestamp: logRecord.ObservedTimestamp(),
+			Severity:          int(logRecord.Severity()),
+			SeverityText:      logRecord.SeverityText(),
+			Body:              encodeLogValue("", logRecord.Body()),
+		}
+		logRecord.WalkAttributes(func(kv otellog.KeyValue) bool {
+			encoded.Attributes = append(encoded.Attributes, encodeLogValue(kv.Key, kv.Value))
+			return true
+		})
+		if traceID := logRecord.TraceID(); traceID.IsValid() {
+			encoded.TraceID = traceID.String()
+			encoded.SpanID = logRecord.SpanID().String()
+			encoded.TraceFlags = byte(logRecord.TraceFlags())
+		}
+
+		if record, err := json.Marshal(encoded); err == nil {
+			records = append(records, record)
+		} else {
+			spoolDropped.Add(1)
+		}
+	}
+	return records
+}
+
+// decodeLogRecords decodes the spool records into log records, dropping corrupted records. The records are rebuilt by
+// emitting them to a logger provider per resource and instrumentation scope, since the SDK only sets them on emitted
+// records.
+func decodeLogRecords(records [][]byte) []sdklog.Record {
+	capture := &recordCapture{records: make([]sdklog.Record, 0, len(records))}
+	loggers := make(map[string]otellog.Logger)
+	for _, record := range records {
+		var encoded spoolLogRecord
+		if err := json.Unmarshal(record, &encoded); err != nil {
+			spoolDropped.Add(1)
+			continue
+		}
+
+		key, _ := json.Marshal(encoded.spoolScope)
+		logger, ok := loggers[string(key)]
+		if !ok {
+			res, scope := encoded.decodeScope()
+			provider := sdklog.NewLoggerProvider(
+				sdklog.WithResource(res),
+				sdklog.WithProcessor(capture),
+				sdklog.WithAttributeCountLimit(-1), // The limits have already been applied to the original record
+				sdklog.WithAttributeValueLengthLimit(-1),
+			)
+			logger = provider.Logger(scope.Name, otellog.WithInstrumentationVersion(scope.Version),
+				otellog.WithSchemaURL(scope.SchemaURL))
+			loggers[string(key)] = logger
+		}
+
+		var logRecord otellog.Record
+		logRecord.SetTimestamp(encoded.Timestamp)
+		logRecord.SetObservedTimestamp(encoded.ObservedTimestamp)
+		logRecord.SetSeverity(otellog.Severity(encoded.Severity))
+		logRecord.SetSeverityText(encoded.SeverityText)
+		logRecord.SetBody(decodeLogValue(encoded.Body))
+		for _, kv := range encoded.Attributes {
+			logRecord.AddAttributes(otellog.KeyValue{Key: kv.Key, Value: decodeLogValue(kv)})
+		}
+
+		// The trace context of the record is taken from the context
+		ctx := context.Background()
+		if traceID, err := oteltrace.TraceIDFromHex(encoded.TraceID); err == nil {
+			spanID, _ := oteltrace.SpanIDFromHex(encoded.SpanID)
+			ctx = oteltrace.ContextWithSpanContext(ctx, oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+				TraceID:    traceID,
+				SpanID:     spanID,
+				TraceFlags: oteltrace.TraceFlags(encoded.TraceFlags),
+			}))
+		}
+
+		logger.Emit(ctx, logRecord)
+	}
+	return capture.records
+}
+
+// recordCapture is a log processor collecting the emitted records.
+type recordCapture struct {
+	records []sdklog.Record
+}
+
+// OnEmit collects a copy of the record.
+func (c *recordCapture) OnEmit(_ context.Context, record *sdklog.Record) error {
+	c.records = append(c.records, record.Clone())
+	return nil
+}
+
+// Shutdown does nothing, since the records are only collected.
+func (c *recordCapture) Shutdown(context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing, since the records are only collected.
+func (c *recordCapture) ForceFlush(context.Context) error {
+	return nil
+}
+
+// encodeScope encodes the resource and instrumentation scope.
+func encodeScope(res *resource.Resource, scope instrumentation.Scope) spoolScope {
+	return spoolScope{
+		Resource:       encodeAttributes(res.Attributes()),
+		SchemaURL:      res.SchemaURL(),
+		ScopeName:      scope.Name,
+		ScopeVersion:   scope.Version,
+		ScopeSchemaURL: scope.SchemaURL,
+	}
+}
+
+// decodeScope decodes the resource and instrumentation scope.
+func (s spoolScope) decodeScope() (*resource.Resource, instrumentation.Scope) {
+	return resource.NewWithAttributes(s.SchemaURL, decodeAttributes(s.Resource)...), instrumentation.Scope{
+		Name:      s.ScopeName,
+		Version:   s.ScopeVersion,
+		SchemaURL: s.ScopeSchemaURL,
+	}
+}
+
+// encodeSpanContext encodes the span context.
+func encodeSpanContext(spanContext oteltrace.SpanContext) spoolSpanContext {
+	if !spanContext.IsValid() {
+		return spoolSpanContext{}
+	}
+	return spoolSpanContext{
+		TraceID:    spanContext.TraceID().String(),
+		SpanID:     spanContext.SpanID().String(),
+		TraceFlags: byte(spanContext.TraceFlags()),
+		TraceState: spanContext.TraceState().String(),
+		Remote:     spanContext.IsRemote(),
+	}
+}
+
+// decode decodes the span context (the empty span context if it was invalid).
+func (s spoolSpanContext) decode() oteltrace.SpanContext {
+	traceID, err := oteltrace.TraceIDFromHex(s.TraceID)
+	if err != nil {
+		return oteltrace.SpanContext{}
+	}
+	spanID, _ := oteltrace.SpanIDFromHex(s.SpanID)
+	traceState, _ := oteltrace.ParseTraceState(s.TraceState)
+
+	return oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: oteltrace.TraceFlags(s.TraceFlags),
+		TraceState: traceState,
+		Remote:     s.Remote,
+	})
+}
+
+// encodeAttributes encodes the attributes with their types.
+func encodeAttributes(attributes []attribute.KeyValue) []spoolKeyValue {
+	encoded := make([]spoolKeyValue, 0, len(attributes))
+	for _, kv := range attributes {
+		value, err := json.Marshal(kv.Value.AsInterface())
+		if err != nil {
+			continue
+		}
+		encoded = append(encoded, spoolKeyValue{Key: string(kv.Key), Type: kv.Value.Type().String(), Value: value})
+	}
+	return encoded
+}
+
+// decodeAttributes decodes the attributes, skipping the attributes that cannot be decoded.
+func decodeAttributes(encoded []spoolKeyValue) []attribute.KeyValue {
+	attributes := make([]attribute.KeyValue, 0, len(encoded))
+	for _, kv := range encoded {
+		var err error
+		var decoded attribute.KeyValue
+		switch kv.Type {
+		case attribute.BOOL.String():
+			var value bool
+			err = json.Unmarshal(kv.Value, &value)
+			decoded = attribute.Bool(kv.Key, value)
+		case attribute.INT64.String():
+			var value int64
+			err = json.Unmarshal(kv.Value, &value)
+			decoded = attribute.Int64(kv.Key, value)
+		case attribute.FLOAT64.String():
+			var value float64
+			err = json.Unmarshal(kv.Value, &value)
+			decoded = attribute.Float64(kv.Key, value)
+		case attribute.BOOLSLICE.String():
+			var value []bool
+			err = json.Unmarshal(kv.Value, &value)
+			decoded = attribute.BoolSlice(kv.Key, value)
+		case attribute.INT64SLICE.String():
+			var value []int64
+			err = json.Unmarshal(kv.Value, &value)
+			decoded = attribute.Int64Slice(kv.Key, value)
+		case attribute.FLOAT64SLICE.String():
+			var value []float64
+			err = json.Unmarshal(kv.Value, &value)
+			decoded = attribute.Float64Slice(kv.Key, value)
+		case attribute.STRINGSLICE.String():
+			var value []string
+			err = json.Unmarshal(kv.Value, &value)
+			decoded = attribute.StringSlice(kv.Key, value)
+		default:
+			var value string
+			err = json.Unmarshal(kv.Value, &value)
+			decoded = attribute.String(kv.Key, value)
+		}
+		if err == nil {
+			attributes = append(attributes, decoded)
+		}
+	}
+	return attributes
+}
+
+// encodeLogValue encodes the value of a log record, keeping the scalar types (other kinds are encoded as string).
+func encodeLogValue(key string, value otellog.Value) spoolKeyValue {
+	var encoded any
+	switch value.Kind() {
+	case otellog.KindBool:
+		encoded = value.AsBool()
+	case otellog.KindInt64:
+		encoded = value.AsInt64()
+	case otellog.KindFloat64:
+		encoded = value.AsFloat64()
+	case otellog.KindString:
+		encoded = value.AsString()
+	default:
+		encoded = value.String()
+	}
+
+	raw, _ := json.Marshal(encoded)
+	return spoolKeyValue{Key: key, Type: value.Kind().String(), Value: raw}
+}
+
+// decodeLogValue decodes the value of a log record.
+func decodeLogValue(kv spoolKeyValue) otellog.Value {
+	switch kv.Type {
+	case otellog.KindBool.String():
+		var value bool
+		_ = json.Unmarshal(kv.Value, &value)
+		return otellog.BoolValue(value)
+	case otellog.KindInt64.String():
+		var value int64
+		_ = json.Unmarshal(kv.Value, &value)
+		return otellog.Int64Value(value)
+	case otellog.KindFloat64.String():
+		var value float64
+		_ = json.Unmarshal(kv.Value, &value)
+		return otellog.Float64Value(value)
+	case otellog.KindEmpty.String():
+		return otellog.Value{}
+	default:
+		var value string
+		_ = json.Unmarshal(kv.Value, &value)
+		return otellog.StringValue(value)
+	}
+}
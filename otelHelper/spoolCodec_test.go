@@ -0,0 +1,132 @@
+package otelHelper
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	spoolResource = resource.NewWithAttributes("", attribute.String("service.name", "spool-test"))
+	spoolTraceID  = oteltrace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	spoolSpanID   = oteltrace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}
+)
+
+func TestSpoolCodecSpans(t *testing.T) {
+	start := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+	stub := tracetest.SpanStub{
+		Name: "HandleOrder",
+		SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+			TraceID: spoolTraceID, SpanID: spoolSpanID, TraceFlags: oteltrace.FlagsSampled,
+		}),
+		SpanKind:  oteltrace.SpanKindServer,
+		StartTime: start,
+		EndTime:   start.Add(time.Second),
+		Attributes: []attribute.KeyValue{
+			attribute.String("http.route", "/order"), attribute.Int64("http.status_code", 500),
+			attribute.Bool("retry", true), attribute.Float64Slice("latencies", []float64{0.5, 1.5}),
+		},
+		Events: []trace.Event{{Name: "log", Time: start, Attributes: []attribute.KeyValue{
+			attribute.String("msg", "Order lookup failed"),
+		}}},
+		Status:               trace.Status{Code: codes.Error, Description: "Internal error"},
+		Resource:             spoolResource,
+		InstrumentationScope: instrumentation.Scope{Name: "FlowWatch", Version: "1.0.0"},
+	}
+
+	decoded := decodeSpans(encodeSpans([]trace.ReadOnlySpan{stub.Snapshot()}))
+	if len(decoded) != 1 {
+		t.Fatalf("Decoded %d spans, want 1", len(decoded))
+	}
+	got := tracetest.SpanStubFromReadOnlySpan(decoded[0])
+
+	if got.Name != stub.Name || got.SpanContext.TraceID() != spoolTraceID || got.SpanKind != stub.SpanKind ||
+		!got.StartTime.Equal(stub.StartTime) || !got.EndTime.Equal(stub.EndTime) || got.Status != stub.Status {
+		t.Errorf("Span not restored: %+v", got)
+	}
+	if !reflect.DeepEqual(got.Attributes, stub.Attributes) {
+		t.Errorf("Attributes %v, want %v", got.Attributes, stub.Attributes)
+	}
+	if len(got.Events) != 1 || !reflect.DeepEqual(got.Events[0].Attributes, stub.Events[0].Attributes) {
+		t.Errorf("Events not restored: %v", got.Events)
+	}
+	if !got.Resource.Equal(spoolResource) || got.InstrumentationScope != stub.InstrumentationScope {
+		t.Errorf("Resource or scope not restored: %v, %v", got.Resource, got.InstrumentationScope)
+	}
+}
+
+func TestSpoolCodecLogRecords(t *testing.T) {
+	var original otellog.Record
+	original.SetTimestamp(time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC))
+	original.SetObservedTimestamp(time.Date(2025, 1, 2, 3, 4, 6, 0, time.UTC))
+	original.SetSeverity(otellog.SeverityWarn)
+	original.SetSeverityText("warning")
+	original.SetBody(otellog.StringValue("Cache miss"))
+	original.AddAttributes(otellog.String("key", "users"), otellog.Int64("size", 42), otellog.Bool("hit", false))
+
+	capture := &recordCapture{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithResource(spoolResource), sdklog.WithProcessor(capture))
+	ctx := oteltrace.ContextWithSpanContext(context.Background(), oteltrace.NewSpanContext(
+		oteltrace.SpanContextConfig{TraceID: spoolTraceID, SpanID: spoolSpanID, TraceFlags: oteltrace.FlagsSampled},
+	))
+	provider.Logger("FlowWatch", otellog.WithInstrumentationVersion("1.0.0")).Emit(ctx, original)
+
+	decoded := decodeLogRecords(encodeLogRecords(capture.records))
+	if len(decoded) != 1 {
+		t.Fatalf("Decoded %d log records, want 1", len(decoded))
+	}
+	want, got := &capture.records[0], &decoded[0]
+
+	if !got.Timestamp().Equal(want.Timestamp()) || !got.ObservedTimestamp().Equal(want.ObservedTimestamp()) ||
+		got.Severity() != want.Severity() || got.SeverityText() != want.SeverityText() ||
+		!got.Body().Equal(want.Body()) {
+		t.Errorf("Log record not restored: %v", got)
+	}
+	if got.TraceID() != spoolTraceID || got.SpanID() != spoolSpanID || got.TraceFlags() != oteltrace.FlagsSampled {
+		t.Errorf("Trace context not restored: %s %s %s", got.TraceID(), got.SpanID(), got.TraceFlags())
+	}
+	if got.AttributesLen() != want.AttributesLen() {
+		t.Errorf("Restored %d attributes, want %d", got.AttributesLen(), want.AttributesLen())
+	}
+	got.WalkAttributes(func(kv otellog.KeyValue) bool {
+		found := false
+		want.WalkAttributes(func(original otellog.KeyValue) bool {
+			found = found || original.Equal(kv)
+			return !found
+		})
+		if !found {
+			t.Errorf("Attribute %v not in the original record", kv)
+		}
+		return true
+	})
+	res := got.Resource()
+	if !res.Equal(spoolResource) {
+		t.Errorf("Resource not restored: %v", res)
+	}
+	if scope := got.InstrumentationScope(); scope.Name != "FlowWatch" || scope.Version != "1.0.0" {
+		t.Errorf("Scope not restored: %v", scope)
+	}
+}
+
+func TestSpoolCodecDropsCorruptedRecords(t *testing.T) {
+	before := spoolDropped.Load()
+	if spans := decodeSpans([][]byte{[]byte("{")}); len(spans) != 0 {
+		t.Errorf("Decoded a corrupted span: %v", spans)
+	}
+	if records := decodeLogRecords([][]byte{[]byte("not json")}); len(records) != 0 {
+		t.Errorf("Decoded a corrupted log record: %v", records)
+	}
+	if got := spoolDropped.Load() - before; got != 2 {
+		t.Errorf("Counted %d dropped records, want 2", got)
+	}
+}
@@ -0,0 +1,110 @@
+package otelHelper
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/pkg/errors"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// InvalidCertificateError is returned if a certificate of the collector connection cannot be loaded.
+var InvalidCertificateError = errors.New("Invalid certificate")
+
+// newTLSConfig returns the TLS config of the collector connection, which trusts the system roots and the CA bundle of
+// OTEL_EXPORTER_OTLP_CERTIFICATE (if set, e.g. for a collector with a certificate of an internal CA) and presents
+// the client certificate (if configured).
+func newTLSConfig() (*tls.Config, error) {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool() // Not available on all platforms
+	}
+
+	if path := Getenv("OTEL_EXPORTER_OTLP_CERTIFICATE"); path != "" {
+		bundle, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(InvalidCertificateError, "Failed to read the CA bundle %s: %v", path, err)
+		}
+		if !roots.AppendCertsFromPEM(bundle) {
+			return nil, errors.Wrapf(InvalidCertificateError, "No PEM certificates found in the CA bundle %s", path)
+		}
+	}
+
+	config := &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+
+	// Present the client certificate to collectors requiring mutual TLS
+	certificate := clientCertificate.Load()
+	if certificate == nil {
+		certPath, keyPath := Getenv("OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE"), Getenv("OTEL_EXPORTER_OTLP_CLIENT_KEY")
+		if certPath != "" || keyPath != "" {
+			loaded, err := loadClientCertificate(certPath, keyPath)
+			if err != nil {
+				return nil, err
+			}
+			certificate = &loaded
+		}
+	}
+	if certificate != nil {
+		config.Certificates = []tls.Certificate{*certificate}
+	}
+
+	return config, nil
+}
+
+var clientCertificate atomic.Pointer[tls.Certificate]
+
+// SetClientCertificate sets the PEM encoded client certificate and key for collectors requiring mutual TLS (e.g. from
+// a secret manager), which take precedence over OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE and
+// OTEL_EXPORTER_OTLP_CLIENT_KEY. The pair is validated immediately. It has to be called before SetupOtelHelper.
+func SetClientCertificate(certPEM, keyPEM []byte) error {
+	certificate, err := parseClientCertificate(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+
+	clientCertificate.Store(&certificate)
+	return nil
+}
+
+// loadClientCertificate loads the client certificate and key from the files.
+func loadClientCertificate(certPath, keyPath string) (tls.Certificate, error) {
+	if certPath == "" || keyPath == "" {
+		return tls.Certificate{}, errors.Wrap(InvalidCertificateError,
+			"OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE and OTEL_EXPORTER_OTLP_CLIENT_KEY must be set together")
+	}
+
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrapf(InvalidCertificateError, "Failed to read the client certificate %s: %v",
+			certPath, err)
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrapf(InvalidCertificateError, "Failed to read the client key %s: %v",
+			keyPath, err)
+	}
+
+	return parseClientCertificate(certPEM, keyPEM)
+}
+
+// parseClientCertificate parses the client certificate and key and verifies that the certificate is valid now, so
+// that a broken configuration fails the setup instead of the first export.
+func parseClientCertificate(certPEM, keyPEM []byte) (tls.Certificate, error) {
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrap(InvalidCertificateError, err.Error())
+	}
+
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return tls.Certificate{}, errors.Wrap(InvalidCertificateError, err.Error())
+	}
+	if now := time.Now(); now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return tls.Certificate{}, errors.Wrapf(InvalidCertificateError, "Client certificate %q is valid from %s to %s",
+			leaf.Subject.CommonName, leaf.NotBefore.Format(time.RFC3339), leaf.NotAfter.Format(time.RFC3339))
+	}
+	certificate.Leaf = leaf
+
+	return certificate, nil
+}
@@ -4,16 +4,25 @@ import (
 	"context"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
 	"log"
 )
 
-func initTraceProvider(serviceName, collectorURL string, supportTLS bool) error {
-	// Check if collector URL is provided
-	if collectorURL == "" {
+func initTraceProvider(serviceName, collectorURL string, supportTLS bool, resourceAttributes ...attribute.KeyValue) error {
+	// Select the exporters of OTEL_TRACES_EXPORTER (OTLP unless disabled) and the ones added with AddTraceExporter
+	exporterNames := getTracesExporterNames()
+	if !exporterNames[ExporterOTLP] {
+		collectorURL = ""
+	}
+	additionalExporters, err := newEnvTraceExporters(exporterNames)
+	if err != nil {
+		return err
+	}
+	additionalExporters = append(additionalExporters, getTraceExporters()...)
+
+	// Check if collector URL or an additional exporter is provided
+	if collectorURL == "" && len(additionalExporters) == 0 {
 		log.Println("Collector URL not provided, skipping trace exporter initialization")
 		// Set up a no-op tracer provider instead
 		noopTP := trace.NewTracerProvider()
@@ -21,34 +30,51 @@ func initTraceProvider(serviceName, collectorURL string, supportTLS bool) error
 		return nil
 	}
 
-	// Create a slice to hold the exporter options
-	var opts []otlptracegrpc.Option
-
-	// Add the collector URL to the exporter options
-	opts = append(opts, otlptracegrpc.WithEndpoint(collectorURL))
-
-	// If the connection is insecure, add the insecure option to the exporter options
-	if !supportTLS { // Thanks to Levin for pointing out the missing exclamation mark
-		opts = append(opts, otlptracegrpc.WithInsecure())
-		log.Println("Insecure connection to the collector")
-	} else {
-		log.Fatal("TLS is not implemented yet")
-		// TODO: Implement TLS connection
-	}
-
 	// Create a slice to hold the trace provider options
 	var tpOptions []trace.TracerProviderOption
+	exportConfig := getSpanExportConfig()
+	batchOptions := exportConfig.batchOptions()
+
+	// Create an OTLP trace exporter (gRPC or HTTP, refer to OTEL_EXPORTER_OTLP_PROTOCOL)
+	var sigNozTraceExporter trace.SpanExporter
+	if collectorURL != "" {
+		sigNozTraceExporter, err = newOTLPTraceExporter(collectorURL, supportTLS, exportConfig)
+		if err != nil {
+			return err
+		}
+		var exporter trace.SpanExporter = monitoredExporter{sigNozTraceExporter}
+		if spool := newSignalSpool("spans"); spool != nil {
+			exporter = spoolingSpanExporter{SpanExporter: exporter, spool: spool} // Keep the spans of failed exports
+		}
+		tpOptions = append(tpOptions, trace.WithBatcher(exporter, batchOptions...))
+	} else {
+		log.Println("Collector URL not provided or OTLP disabled, skipping the OTLP trace exporter")
+	}
 
-	// Create an OTLP trace exporter
-	sigNozTraceExporter, err := otlptracegrpc.New(context.Background(), opts...)
-	if err != nil {
-		err = errors.Wrap(err, "Failed to create OTLP exporter")
-		return err
+	// Add the additional exporters, each with its own batch span processor, so that a slow exporter does not delay
+	// the others
+	processors := make(map[string]trace.SpanProcessor, len(additionalExporters))
+	for _, additional := range additionalExporters {
+		processor := trace.NewBatchSpanProcessor(additional.exporter, batchOptions...)
+		processors[additional.name] = processor
+		tpOptions = append(tpOptions, trace.WithSpanProcessor(processor))
 	}
-	tpOptions = append(tpOptions, trace.WithBatcher(sigNozTraceExporter))
 
-	// Set the service name
-	tpOptions = append(tpOptions, trace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))))
+	// Sample the spans with the configured sampler (by default according to the parent span, limited by
+	// SetMaxSamplingRatio and reduced for the root spans of noisy paths), unless sampling has been forced with ForceSample
+	tpOptions = append(tpOptions, trace.WithSampler(newSampler(getSamplerConfig())))
+
+	// Bound the attributes, events and links of the spans
+	tpOptions = append(tpOptions, trace.WithRawSpanLimits(getSpanLimits()))
+
+	// Set the service name and the additional resource attributes
+	res := newResource(serviceName, resourceAttributes...)
+	tpOptions = append(tpOptions, trace.WithResource(res))
+
+	// Check the OTLP exporter with HealthCheck
+	if sigNozTraceExporter != nil {
+		health.Store(&healthTarget{exporter: sigNozTraceExporter, resource: res})
+	}
 
 	// Create a new trace provider with the configured options
 	tp := trace.NewTracerProvider(tpOptions...)
@@ -57,15 +83,18 @@ func initTraceProvider(serviceName, collectorURL string, supportTLS bool) error
 	otel.SetTracerProvider(tp)
 
 	// Add the shutdown function to the global slice
-	shutdown := func() error {
+	shutdown := func(ctx context.Context) error {
 		// Shutdown the tracer provider to flush any remaining spans
-		err1 := tp.Shutdown(context.Background())
+		err1 := tp.Shutdown(ctx)
 		if err1 != nil {
 			err1 = errors.Wrap(err1, "Failed to shut down the tracer provider.")
 		}
 
 		// Shutdown the SigNoz exporter to ensure all spans are sent
-		err2 := sigNozTraceExporter.Shutdown(context.Background())
+		var err2 error
+		if sigNozTraceExporter != nil {
+			err2 = sigNozTraceExporter.Shutdown(ctx)
+		}
 		if err2 != nil {
 			err2 = errors.Wrap(err2, "Failed to shut down the SigNoz exporter.")
 		}
@@ -80,7 +109,18 @@ func initTraceProvider(serviceName, collectorURL string, supportTLS bool) error
 		return err2
 	}
 
-	shutdownFuncs = append(shutdownFuncs, shutdown)
+	RegisterShutdown("tracer provider", DefaultShutdownBudget, shutdown)
+
+	// Shut down the additional exporters independently, so that a stuck one is abandoned within its own budget
+	// (shutting down a processor twice is a no-op)
+	for name, processor := range processors {
+		RegisterShutdown("trace exporter "+name, DefaultShutdownBudget, func(ctx context.Context) error {
+			return errors.Wrapf(processor.Shutdown(ctx), "Failed to shut down the trace exporter %s", name)
+		})
+	}
+	flushFuncs = append(flushFuncs, func(ctx context.Context) error {
+		return errors.Wrap(tp.ForceFlush(ctx), "Failed to flush the tracer provider")
+	})
 
 	return nil
 }
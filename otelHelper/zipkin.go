@@ -0,0 +1,41 @@
+package otelHelper
+
+import (
+	"go.opentelemetry.io/otel/exporters/zipkin"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"log"
+	"net/http"
+)
+
+// DefaultZipkinEndpoint is the span endpoint of a local Zipkin server.
+const DefaultZipkinEndpoint = "http://localhost:9411/api/v2/spans"
+
+// ZipkinConfig configures the span exporter for Zipkin-compatible backends.
+type ZipkinConfig struct {
+	Endpoint string            // Span endpoint (default: DefaultZipkinEndpoint)
+	Headers  map[string]string // Additional headers of the requests, e.g. for authentication
+	Client   *http.Client      // HTTP client (default: http.DefaultClient)
+}
+
+// ZipkinConfigFromEnv reads the ZipkinConfig from the standard environment variables.
+func ZipkinConfigFromEnv() ZipkinConfig {
+	return ZipkinConfig{Endpoint: Getenv("OTEL_EXPORTER_ZIPKIN_ENDPOINT")}
+}
+
+// NewZipkinExporter creates a span exporter sending to the Zipkin endpoint via HTTP. The service name is taken from
+// the resource of the tracer provider like for OTLP. It can be added with AddTraceExporter or selected with
+// OTEL_TRACES_EXPORTER=zipkin.
+func NewZipkinExporter(config ZipkinConfig) (trace.SpanExporter, error) {
+	if config.Endpoint == "" {
+		config.Endpoint = DefaultZipkinEndpoint
+	}
+
+	opts := []zipkin.Option{zipkin.WithLogger(log.Default())}
+	if len(config.Headers) > 0 {
+		opts = append(opts, zipkin.WithHeaders(config.Headers))
+	}
+	if config.Client != nil {
+		opts = append(opts, zipkin.WithClient(config.Client))
+	}
+	return zipkin.New(config.Endpoint, opts...)
+}
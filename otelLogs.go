@@ -0,0 +1,63 @@
+package FlowWatch
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"time"
+)
+
+// otelLogger emits the log records of the entries without a recording span (no-op until otelHelper sets up the
+// logger provider).
+var otelLogger = global.Logger("github.com/LucaSchmitz2003/FlowWatch")
+
+// otelSeverities maps the levels to the severity numbers of OpenTelemetry.
+var otelSeverities = map[Level]otellog.Severity{
+	Trace: otellog.SeverityTrace,
+	Debug: otellog.SeverityDebug,
+	Info:  otellog.SeverityInfo,
+	Warn:  otellog.SeverityWarn,
+	Error: otellog.SeverityError,
+	Fatal: otellog.SeverityFatal,
+	Panic: otellog.SeverityFatal2,
+}
+
+// emitLogRecord exports the entry as OpenTelemetry log record. The record is correlated with the trace of the
+// context if there is one (e.g. a remote or unsampled parent).
+func emitLogRecord(ctx context.Context, level Level, msg string, data map[string]interface{}, t time.Time) {
+	var record otellog.Record
+	record.SetTimestamp(t)
+	record.SetObservedTimestamp(time.Now())
+	record.SetSeverity(otelSeverities[level])
+	record.SetSeverityText(level.getLogrusLevel().String())
+	record.SetBody(otellog.StringValue(msg))
+
+	for key, value := range data {
+		switch key {
+		case "file":
+			record.AddAttributes(otellog.String("code.filepath", fmt.Sprint(value)))
+		case "line":
+			record.AddAttributes(otellog.String("code.lineno", fmt.Sprint(value)))
+		case ErrorKey:
+			if err, ok := value.(error); ok {
+				record.AddAttributes(
+					otellog.String("exception.type", fmt.Sprintf("%T", errors.Cause(err))),
+					otellog.String("exception.message", err.Error()),
+				)
+			}
+		case StackKey:
+			record.AddAttributes(otellog.String("exception.stacktrace", fmt.Sprint(value)))
+		case TraceIDKey, SpanIDKey:
+			continue // The record is correlated with the trace of the context
+		default:
+			record.AddAttributes(otellog.String(key, fmt.Sprint(value)))
+		}
+	}
+
+	if ctx == nil {
+		ctx = context.Background() // Entries of the logrus API without context
+	}
+	otelLogger.Emit(ctx, record)
+}
@@ -0,0 +1,68 @@
+package FlowWatch
+
+import (
+	"io"
+	"os"
+)
+
+// OutputRouter routes the entries of a LogHelper to different writers depending on their level, e.g. warnings and
+// above to stderr and the rest to stdout, since container platforms treat the two streams differently.
+type OutputRouter struct {
+	Writers map[Level]io.Writer // Writers per level
+	Default io.Writer           // Writer of the levels without a writer (default: discard)
+}
+
+// NewStdStreamRouter returns a router writing trace, debug and info entries to stdout and warnings and above to stderr.
+func NewStdStreamRouter() *OutputRouter {
+	return &OutputRouter{
+		Writers: map[Level]io.Writer{
+			Trace: os.Stdout, Debug: os.Stdout, Info: os.Stdout,
+			Warn: os.Stderr, Error: os.Stderr, Fatal: os.Stderr, Panic: os.Stderr,
+		},
+	}
+}
+
+// WithOutputRouter routes the entries to the writers of the router depending on their level (refer to WithOutput).
+// It is only supported by the LogrusBackend.
+func WithOutputRouter(router *OutputRouter) Option {
+	return func(o *options) {
+		o.output = router
+		o.router = router
+	}
+}
+
+// writer returns the writer of the level.
+func (r *OutputRouter) writer(level Level) io.Writer {
+	if writer, ok := r.Writers[level]; ok {
+		return writer
+	}
+	if r.Default != nil {
+		return r.Default
+	}
+	return io.Discard
+}
+
+// Write writes the entries whose level is not known to the default writer, e.g. entries written directly to the
+// logrus logger of the LogrusBackend (the entries of the LogHelper are written to the writer of their level).
+func (r *OutputRouter) Write(p []byte) (int, error) {
+	if r.Default == nil {
+		return len(p), nil
+	}
+	return r.Default.Write(p)
+}
+
+// Sync syncs all writers which support it (e.g. files, refer to syncWriter).
+func (r *OutputRouter) Sync() error {
+	writers := []io.Writer{r.Default}
+	for _, writer := range r.Writers {
+		writers = append(writers, writer)
+	}
+
+	var firstErr error
+	for _, writer := range writers {
+		if err := syncWriter(writer); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
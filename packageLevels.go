@@ -0,0 +1,160 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// InvalidLevelSpecError is returned if a level spec cannot be parsed.
+var InvalidLevelSpecError = errors.New("Invalid level spec")
+
+// levelRule is a package pattern with its log level.
+type levelRule struct {
+	pattern string
+	level   Level
+}
+
+// packageLevels holds the parsed level spec of a LogHelper.
+type packageLevels struct {
+	mu           sync.RWMutex
+	defaultLevel Level       // Level of the packages without a matching rule (the "*" rule)
+	rules        []levelRule // Rules ordered from the most to the least specific pattern
+	packages     sync.Map    // Cache of the resolved package levels by program counter (uintptr -> *Level or nil)
+}
+
+// SetLevelSpec configures per-package log levels with a spec like "*=info,internal/payments=debug,vendor/*=error".
+// The patterns are matched against the trailing path segments of the calling package using path.Match, the most
+// specific (longest) matching pattern wins and "*" sets the level of all other packages. An empty spec removes the
+// per-package levels. The spec of the shared LogHelper instance is read from FLOWWATCH_LEVELS.
+func (lh *LogHelper) SetLevelSpec(spec string) error {
+	if strings.TrimSpace(spec) == "" {
+		if levels := lh.packageLevels.Swap(nil); levels != nil {
+			lh.backend.SetLevel(levels.getDefaultLevel())
+		}
+		return nil
+	}
+
+	levels := &packageLevels{defaultLevel: lh.GetLevel()}
+	for _, item := range strings.Split(spec, ",") {
+		pattern, name, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || pattern == "" {
+			return errors.Wrapf(InvalidLevelSpecError, "Failed to parse %q", item)
+		}
+		level, err := ParseLevel(strings.TrimSpace(name))
+		if err != nil {
+			return errors.Wrap(InvalidLevelSpecError, err.Error())
+		}
+
+		pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+		if pattern == "*" {
+			levels.defaultLevel = lh.clampPolicyLevel(level)
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Wrapf(InvalidLevelSpecError, "Pattern %q: %v", pattern, err)
+		}
+		levels.rules = append(levels.rules, levelRule{pattern: pattern, level: level})
+	}
+
+	// Order the rules from the most to the least specific pattern
+	for i := 1; i < len(levels.rules); i++ {
+		for j := i; j > 0 && len(levels.rules[j].pattern) > len(levels.rules[j-1].pattern); j-- {
+			levels.rules[j], levels.rules[j-1] = levels.rules[j-1], levels.rules[j]
+		}
+	}
+
+	lh.packageLevels.Store(levels)
+	lh.backend.SetLevel(levels.minLevel())
+
+	return nil
+}
+
+// SetLevelSpec configures per-package log levels of the shared LogHelper instance (refer to LogHelper.SetLevelSpec).
+func SetLevelSpec(spec string) error {
+	return GetLogHelper().SetLevelSpec(spec)
+}
+
+// applyLevelSpecFromEnv applies the level spec from FLOWWATCH_LEVELS (if set) and logs an invalid spec.
+func (lh *LogHelper) applyLevelSpecFromEnv(spec string) {
+	if spec == "" {
+		return
+	}
+	if err := lh.SetLevelSpec(spec); err != nil {
+		lh.WithError(context.Background(), err).Warn("Failed to apply FLOWWATCH_LEVELS, ignoring it")
+	}
+}
+
+// minLevel returns the most verbose level of all rules, which is the level the backend has to accept.
+func (levels *packageLevels) minLevel() Level {
+	minimum := levels.getDefaultLevel()
+	for _, rule := range levels.rules {
+		minimum = leastSevere(minimum, rule.level)
+	}
+	return minimum
+}
+
+// isEnabled reports whether entries at the level are logged for the caller frame identified by the program counter.
+func (levels *packageLevels) isEnabled(level Level, pc uintptr, frame runtime.Frame) bool {
+	if cached, ok := levels.packages.Load(pc); ok {
+		if packageLevel := cached.(*Level); packageLevel != nil {
+			return level.AtLeast(*packageLevel)
+		}
+		return level.AtLeast(levels.getDefaultLevel())
+	}
+
+	packageLevel := levels.match(functionPackage(frame.Function))
+	levels.packages.Store(pc, packageLevel)
+	if packageLevel != nil {
+		return level.AtLeast(*packageLevel)
+	}
+	return level.AtLeast(levels.getDefaultLevel())
+}
+
+// match returns the level of the most specific rule matching the package or nil if no rule matches.
+func (levels *packageLevels) match(pkg string) *Level {
+	segments := strings.Split(pkg, "/")
+	for _, rule := range levels.rules {
+		for i := range segments {
+			if matched, _ := path.Match(rule.pattern, strings.Join(segments[i:], "/")); matched {
+				return &rule.level
+			}
+		}
+	}
+	return nil
+}
+
+// getDefaultLevel returns the level of the packages without a matching rule.
+func (levels *packageLevels) getDefaultLevel() Level {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+
+	return levels.defaultLevel
+}
+
+// setDefaultLevel updates the level of the packages without a matching rule.
+func (levels *packageLevels) setDefaultLevel(level Level) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	levels.defaultLevel = level
+}
+
+// isEnabled reports whether entries at the level are logged for the caller of the log function, taking the
+// per-package levels into account.
+func (lh *LogHelper) isEnabled(level Level) bool {
+	levels := lh.packageLevels.Load()
+	if levels == nil {
+		return lh.backend.IsLevelEnabled(level)
+	}
+
+	// Walk the frames instead of using a fixed depth, since adapters like the level writer add frames
+	pc, frame, ok := callerFrame()
+	if !ok {
+		return level.AtLeast(levels.getDefaultLevel())
+	}
+	return levels.isEnabled(level, pc, frame)
+}
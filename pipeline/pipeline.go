@@ -0,0 +1,118 @@
+// Package pipeline provides tracing for multi-stage processing built on channels (e.g. streaming and ETL services).
+// Every item carries its trace context between the stages, each stage processes an item in its own span, the time
+// items spend in the queues between the stages is recorded as a metric and failed items are logged per stage.
+//
+// The stages are compatible with errgroup:
+//
+//	g, ctx := errgroup.WithContext(ctx)
+//	parsed := make(chan pipeline.Item[Record])
+//	g.Go(pipeline.Stage(ctx, "parse", lines, parsed, parse))
+//	g.Go(pipeline.Stage(ctx, "store", parsed, stored, store))
+package pipeline
+
+import (
+	"context"
+	"github.com/LucaSchmitz2003/FlowWatch"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"time"
+)
+
+var (
+	tracer = otel.Tracer("FlowWatch/pipeline")
+	meter  = otel.Meter("FlowWatch/pipeline")
+	logger = FlowWatch.GetLogHelper()
+)
+
+// stageKey is the attribute key of the stage name.
+const stageKey = "pipeline.stage"
+
+// Item is a value passed between the stages together with its trace context and the time it was enqueued.
+type Item[T any] struct {
+	Ctx      context.Context
+	Value    T
+	enqueued time.Time
+}
+
+// NewItem wraps the value with its trace context to be sent to a stage.
+func NewItem[T any](ctx context.Context, value T) Item[T] {
+	return Item[T]{Ctx: ctx, Value: value, enqueued: time.Now()}
+}
+
+// Send enqueues the value with its trace context, blocking until the next stage accepts it or the context is done.
+func Send[T any](ctx context.Context, out chan<- Item[T], value T) error {
+	select {
+	case out <- NewItem(ctx, value):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// StageFunc processes a single item of a stage. The context contains the span of the stage.
+type StageFunc[In, Out any] func(ctx context.Context, value In) (Out, error)
+
+// Stage returns a function (e.g. for errgroup.Group.Go), which processes the items from in with fn and sends the
+// results to out. Items for which fn fails are logged and dropped. The function returns when in is closed (nil) or
+// the context is done (its error) and closes out in both cases.
+func Stage[In, Out any](ctx context.Context, name string, in <-chan Item[In], out chan<- Item[Out], fn StageFunc[In, Out]) func() error {
+	return func() error {
+		defer close(out)
+
+		stageAttributes := metric.WithAttributes(attribute.String(stageKey, name))
+		queueLatency, err := meter.Float64Histogram("pipeline.queue.latency",
+			metric.WithDescription("Time items spent in the queue before the stage processed them"),
+			metric.WithUnit("s"))
+		if err != nil {
+			logger.WithError(ctx, err).Warn("Failed to create the pipeline queue latency histogram")
+		}
+
+		for {
+			var item Item[In]
+			var ok bool
+			select {
+			case item, ok = <-in:
+				if !ok {
+					return nil
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			if queueLatency != nil && !item.enqueued.IsZero() {
+				queueLatency.Record(ctx, time.Since(item.enqueued).Seconds(), stageAttributes)
+			}
+
+			result, err := process(name, item, fn)
+			if err != nil {
+				continue // The failure has already been logged and recorded on the span of the item
+			}
+
+			select {
+			case out <- NewItem(item.Ctx, result):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// process runs the stage function for the item in a span of the stage and logs a failure.
+func process[In, Out any](name string, item Item[In], fn StageFunc[In, Out]) (Out, error) {
+	itemCtx := item.Ctx
+	if itemCtx == nil {
+		itemCtx = context.Background()
+	}
+
+	spanCtx, span := tracer.Start(itemCtx, name)
+	defer span.End()
+	span.SetAttributes(attribute.String(stageKey, name))
+
+	result, err := fn(spanCtx, item.Value)
+	if err != nil {
+		logger.WithError(spanCtx, err).WithField(stageKey, name).Error("Pipeline stage failed")
+	}
+
+	return result, err
+}
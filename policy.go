@@ -0,0 +1,107 @@
+package FlowWatch
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"github.com/pkg/errors"
+	"sync/atomic"
+)
+
+// InvalidPolicyError is returned if a policy cannot be parsed.
+var InvalidPolicyError = errors.New("Invalid policy")
+
+// Policy specifies the allowed telemetry settings per environment, so that an organization can govern the services
+// using FlowWatch centrally (e.g. by embedding a policy file). Example:
+//
+//	{"environments": {"prod": {"min_level": "info", "max_sampling_ratio": 0.5,
+//		"default_sampler": {"name": "parentbased_traceidratio", "ratio": 0.1}}}}
+type Policy struct {
+	Environments map[string]EnvironmentPolicy `json:"environments"`
+}
+
+// EnvironmentPolicy specifies the allowed telemetry settings of an environment.
+type EnvironmentPolicy struct {
+	// MinLevel is the most verbose global log level allowed (e.g. "info" to forbid Debug globally). Empty means unlimited.
+	MinLevel string `json:"min_level,omitempty"`
+
+	// MaxSamplingRatio is the maximum fraction of sampled traces. Nil means unlimited.
+	MaxSamplingRatio *float64 `json:"max_sampling_ratio,omitempty"`
+
+	// DefaultSampler is the sampler of the services which select none themselves (refer to
+	// otelHelper.SetDefaultSampler). Nil means the default of otelHelper.
+	DefaultSampler *otelHelper.SamplerConfig `json:"default_sampler,omitempty"`
+}
+
+var minPolicyLevel atomic.Pointer[Level]
+
+// LoadPolicy parses a JSON policy (e.g. an embedded policy file).
+func LoadPolicy(data []byte) (Policy, error) {
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return Policy{}, errors.Wrap(InvalidPolicyError, err.Error())
+	}
+
+	for environment, environmentPolicy := range policy.Environments {
+		if environmentPolicy.MinLevel != "" {
+			if _, err := ParseLevel(environmentPolicy.MinLevel); err != nil {
+				return Policy{}, errors.Wrapf(InvalidPolicyError, "Environment %s: %v", environment, err)
+			}
+		}
+		if environmentPolicy.DefaultSampler != nil {
+			if err := environmentPolicy.DefaultSampler.Validate(); err != nil {
+				return Policy{}, errors.Wrapf(InvalidPolicyError, "Environment %s: %v", environment, err)
+			}
+		}
+	}
+
+	return policy, nil
+}
+
+// ApplyPolicy enforces the policy of the environment. The current settings are clamped to the allowed ranges and
+// every later violation (e.g. SetLogLevel(Debug) in an environment with the minimum level Info) is logged and clamped.
+// Environments without a policy are not restricted. The default sampler of the environment only applies if the policy
+// is applied before SetupOtelHelper.
+func ApplyPolicy(policy Policy, environment string) {
+	ctx := context.Background()
+	environmentPolicy := policy.Environments[environment]
+
+	// Enforce the minimum log level
+	if environmentPolicy.MinLevel != "" {
+		minLevel, _ := ParseLevel(environmentPolicy.MinLevel) // Validated by LoadPolicy, unknown levels fall back to Debug
+		minPolicyLevel.Store(&minLevel)
+	} else {
+		minPolicyLevel.Store(nil)
+	}
+	lh := GetLogHelper()
+	lh.SetLevel(lh.GetLevel()) // Clamp the current level
+
+	// Select the default sampler before the maximum sampling ratio, which warns about the samplers it clamps
+	_ = otelHelper.SetDefaultSampler(environmentPolicy.DefaultSampler) // Validated by LoadPolicy
+
+	// Enforce the maximum sampling ratio
+	if environmentPolicy.MaxSamplingRatio != nil {
+		otelHelper.SetMaxSamplingRatio(*environmentPolicy.MaxSamplingRatio)
+		lh.WithFields(ctx, Fields{
+			"environment":        environment,
+			"max_sampling_ratio": *environmentPolicy.MaxSamplingRatio,
+		}).Info("Sampling limited by policy")
+	} else {
+		otelHelper.SetMaxSamplingRatio(1)
+	}
+}
+
+// clampPolicyLevel returns the level clamped to the minimum level of the active policy and logs the violation.
+func (lh *LogHelper) clampPolicyLevel(level Level) Level {
+	minLevel := minPolicyLevel.Load()
+	if minLevel == nil || level.AtLeast(*minLevel) || lh.name != "" {
+		return level // Named loggers are not restricted, since the policy governs the global level
+	}
+
+	lh.WithFields(context.Background(), Fields{
+		"requested_level": level.String(),
+		"policy_level":    minLevel.String(),
+	}).Warn("Log level violates the policy, clamping it")
+
+	return *minLevel
+}
@@ -0,0 +1,141 @@
+package FlowWatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCPUProfileDuration is the default duration of a CPU profile.
+const DefaultCPUProfileDuration = 30 * time.Second
+
+// maxCPUProfileDuration bounds the duration of a CPU profile requested via the endpoint.
+const maxCPUProfileDuration = 5 * time.Minute
+
+var (
+	// UnknownProfileError is returned if the requested profile is not supported.
+	UnknownProfileError = errors.New("Unknown profile")
+
+	// ProfileInProgressError is returned if a CPU profile is requested while another one is running.
+	ProfileInProgressError = errors.New("CPU profile already in progress")
+)
+
+var (
+	profileDir atomic.Pointer[string]
+	cpuProfile sync.Mutex // Only one CPU profile can be recorded at a time
+)
+
+// SetProfileDir sets the directory to which the profiles are written (default: the temporary directory).
+func SetProfileDir(dir string) {
+	profileDir.Store(&dir)
+}
+
+// CaptureProfile writes the profile of the given kind (cpu, heap, goroutine, allocs, block, mutex or threadcreate)
+// to the profile directory and returns its path. CPU profiles are recorded for the given duration. The path is logged
+// and added to the span from the context as event, so that the profile can be found from the incident.
+func CaptureProfile(ctx context.Context, kind string, duration time.Duration) (string, error) {
+	if kind != "cpu" && pprof.Lookup(kind) == nil {
+		return "", errors.Wrap(UnknownProfileError, kind)
+	}
+
+	dir := os.TempDir()
+	if configured := profileDir.Load(); configured != nil {
+		dir = *configured
+	}
+	name := fmt.Sprintf("flowwatch-%s-%d-%s.pprof", kind, os.Getpid(), time.Now().Format("20060102T150405"))
+	path := filepath.Join(dir, name)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create the profile file")
+	}
+	defer file.Close()
+
+	if kind == "cpu" {
+		err = writeCPUProfile(ctx, file, duration)
+	} else {
+		if kind == "heap" {
+			runtime.GC() // Get up-to-date statistics
+		}
+		err = pprof.Lookup(kind).WriteTo(file, 0)
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", errors.Wrapf(err, "Failed to write the %s profile", kind)
+	}
+
+	GetLogHelper().WithFields(ctx, Fields{
+		"profile":      kind,
+		"profile_path": path,
+	}).Warn("Profile captured")
+
+	return path, nil
+}
+
+// writeCPUProfile records a CPU profile for the duration or until the context is canceled.
+func writeCPUProfile(ctx context.Context, file *os.File, duration time.Duration) error {
+	if !cpuProfile.TryLock() {
+		return ProfileInProgressError
+	}
+	defer cpuProfile.Unlock()
+
+	if err := pprof.StartCPUProfile(file); err != nil {
+		return err
+	}
+	defer pprof.StopCPUProfile()
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	return nil
+}
+
+// ProfileHandler returns an http.Handler for capturing profiles during incidents, which is meant to be mounted next
+// to the LevelHandler on the admin endpoint:
+//
+//	POST ?profile=heap captures a heap profile: {"profile":"heap","path":"/tmp/flowwatch-heap-1-20250101T120000.pprof"}
+//	POST ?profile=cpu&seconds=10 records a CPU profile for 10 seconds (default: 30 seconds)
+func ProfileHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeLevelError(w, http.StatusMethodNotAllowed, "Only POST is supported.")
+			return
+		}
+
+		kind := r.FormValue("profile")
+		duration := DefaultCPUProfileDuration
+		if seconds, err := strconv.Atoi(r.FormValue("seconds")); err == nil && seconds > 0 {
+			duration = min(time.Duration(seconds)*time.Second, maxCPUProfileDuration)
+		}
+
+		path, err := CaptureProfile(r.Context(), kind, duration)
+		switch {
+		case errors.Is(err, UnknownProfileError):
+			writeLevelError(w, http.StatusBadRequest, err.Error())
+			return
+		case errors.Is(err, ProfileInProgressError):
+			writeLevelError(w, http.StatusConflict, err.Error())
+			return
+		case err != nil:
+			writeLevelError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"profile": kind, "path": path})
+	})
+}
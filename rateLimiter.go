@@ -0,0 +1,131 @@
+package FlowWatch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRateLimitKeys is the number of tracked messages above which expired windows are pruned.
+const maxRateLimitKeys = 10000
+
+// rateLimiter limits identical entries (same level, message and key fields) to a number per interval.
+type rateLimiter struct {
+	mu        sync.Mutex
+	lh        *LogHelper
+	burst     int
+	interval  time.Duration
+	keyFields []string
+	windows   map[string]*rateWindow
+}
+
+// rateWindow counts the occurrences of an entry within the current interval.
+type rateWindow struct {
+	start      time.Time
+	count      int
+	suppressed int
+	level      Level
+	msg        string
+}
+
+// SetRateLimit limits identical entries (same level, message and values of the key fields) to burst entries per
+// interval, e.g. to prevent retry loops from flooding the output and the span events. Suppressed duplicates are
+// summarized in a "Suppressed duplicates" entry at the end of the interval. A burst of zero or less disables the limit.
+func (lh *LogHelper) SetRateLimit(burst int, interval time.Duration, keyFields ...string) {
+	if burst <= 0 || interval <= 0 {
+		lh.root.rateLimiter.Store(nil)
+		return
+	}
+
+	lh.root.rateLimiter.Store(&rateLimiter{
+		lh:        lh.root,
+		burst:     burst,
+		interval:  interval,
+		keyFields: keyFields,
+		windows:   make(map[string]*rateWindow),
+	})
+}
+
+// SetRateLimit limits identical entries of the shared LogHelper instance (refer to LogHelper.SetRateLimit).
+func SetRateLimit(burst int, interval time.Duration, keyFields ...string) {
+	GetLogHelper().SetRateLimit(burst, interval, keyFields...)
+}
+
+// isAllowed reports whether the entry is within the rate limit (always true if no limit is configured).
+func (lh *LogHelper) isAllowed(level Level, fields Fields, msg string) bool {
+	limiter := lh.root.rateLimiter.Load()
+	if limiter == nil || level.AtLeast(Fatal) {
+		return true // Fatal and panic entries must never be suppressed, since they terminate the program
+	}
+	return limiter.allow(level, fields, msg)
+}
+
+// allow counts the entry and reports whether it is within the rate limit.
+func (l *rateLimiter) allow(level Level, fields Fields, msg string) bool {
+	key := l.key(level, fields, msg)
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	window, ok := l.windows[key]
+	if !ok || now.Sub(window.start) >= l.interval {
+		if len(l.windows) >= maxRateLimitKeys {
+			l.prune(now)
+		}
+		l.windows[key] = &rateWindow{start: now, count: 1, level: level, msg: msg}
+		return true
+	}
+
+	window.count++
+	if window.count <= l.burst {
+		return true
+	}
+
+	// Schedule the summary at the end of the interval on the first suppression
+	window.suppressed++
+	if window.suppressed == 1 {
+		time.AfterFunc(l.interval-now.Sub(window.start), func() {
+			l.summarize(key, window)
+		})
+	}
+
+	return false
+}
+
+// summarize logs the number of suppressed duplicates of the window and removes it.
+func (l *rateLimiter) summarize(key string, window *rateWindow) {
+	l.mu.Lock()
+	suppressed := window.suppressed
+	if l.windows[key] == window {
+		delete(l.windows, key)
+	}
+	l.mu.Unlock()
+
+	msg := fmt.Sprintf("Suppressed %d duplicates of: %s", suppressed, window.msg)
+	l.lh.backend.Log(window.level, context.Background(), Fields{"suppressed": suppressed}, msg)
+}
+
+// prune removes all windows whose interval has passed and which have no pending summary.
+func (l *rateLimiter) prune(now time.Time) {
+	for key, window := range l.windows {
+		if now.Sub(window.start) >= l.interval && window.suppressed == 0 {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// key returns the identity of an entry for the duplicate detection.
+func (l *rateLimiter) key(level Level, fields Fields, msg string) string {
+	var builder strings.Builder
+	builder.WriteString(level.String())
+	builder.WriteByte(0)
+	builder.WriteString(msg)
+	for _, field := range l.keyFields {
+		builder.WriteByte(0)
+		fmt.Fprint(&builder, fields[field])
+	}
+	return builder.String()
+}
@@ -0,0 +1,52 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"go.opentelemetry.io/otel/attribute"
+	"reflect"
+)
+
+// recordedErrorKey is the context key marking that the error of the entry has already been recorded on the span by
+// otelHelper.RecordError.
+type recordedErrorKey struct{}
+
+func init() {
+	otelHelper.OnRecordError(logRecordedError)
+	otelHelper.SetErrorRedaction(redactRecordedError)
+}
+
+// redactRecordedError masks the error recorded with otelHelper.RecordError with the redaction of the shared LogHelper
+// instance (refer to SetRedaction).
+func redactRecordedError(err error) error {
+	r := GetLogHelper().redactor.Load()
+	if r == nil {
+		return err
+	}
+	if redacted, ok := r.redactNested(reflect.ValueOf(err), 0); ok {
+		return redacted.(error)
+	}
+	return err
+}
+
+// logRecordedError logs the error recorded with otelHelper.RecordError at error level with the attributes as fields.
+// The context marks the error as recorded, so that the LogrusOtelHook does not record it on the span again.
+func logRecordedError(ctx context.Context, err error, attributes []attribute.KeyValue) {
+	fields := make(Fields, len(attributes))
+	for _, kv := range attributes {
+		fields[string(kv.Key)] = kv.Value.AsInterface()
+	}
+
+	ctx = context.WithValue(ctx, recordedErrorKey{}, true)
+	GetLogHelper().WithError(ctx, err).WithFields(fields).Error(err.Error())
+}
+
+// isRecordedError returns whether the error of the entry has already been recorded on the span by
+// otelHelper.RecordError.
+func isRecordedError(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	recorded, _ := ctx.Value(recordedErrorKey{}).(bool)
+	return recorded
+}
@@ -0,0 +1,119 @@
+package FlowWatch
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"github.com/pkg/errors"
+	"runtime"
+)
+
+// RecoverAndLog recovers a panic of the goroutine, logs it with its stack trace, records it on the span from the
+// context and flushes the telemetry. It has to be deferred directly: defer FlowWatch.RecoverAndLog(ctx)
+func RecoverAndLog(ctx context.Context) {
+	if value := recover(); value != nil {
+		GetLogHelper().logRecovered(ctx, value)
+	}
+}
+
+// RecoverAndLogRepanic is like RecoverAndLog, but panics again with the recovered value afterward.
+func RecoverAndLogRepanic(ctx context.Context) {
+	if value := recover(); value != nil {
+		GetLogHelper().logRecovered(ctx, value)
+		panic(value)
+	}
+}
+
+// RecoverAndLog recovers a panic of the goroutine and logs it with the LogHelper (refer to RecoverAndLog).
+func (lh *LogHelper) RecoverAndLog(ctx context.Context) {
+	if value := recover(); value != nil {
+		lh.logRecovered(ctx, value)
+	}
+}
+
+// RecoverAndLogRepanic recovers a panic of the goroutine, logs it with the LogHelper and panics again afterward.
+func (lh *LogHelper) RecoverAndLogRepanic(ctx context.Context) {
+	if value := recover(); value != nil {
+		lh.logRecovered(ctx, value)
+		panic(value)
+	}
+}
+
+// recoveredPanicKey marks the context of entries logging a recovered panic, which must not shut down the
+// OpenTelemetry connection like other panic entries since the program continues.
+type recoveredPanicKey struct{}
+
+// isRecoveredPanic reports whether the context belongs to an entry logging a recovered panic.
+func isRecoveredPanic(ctx context.Context) bool {
+	return ctx != nil && ctx.Value(recoveredPanicKey{}) != nil
+}
+
+// panicError is a recovered panic value with the stack of the panicking goroutine, which NewErrorObject adds to the
+// ErrorObject unless the value is an error with a stack of its own.
+type panicError struct {
+	err   error
+	stack []uintptr
+}
+
+func (e *panicError) Error() string {
+	return "Recovered from a panic: " + e.err.Error()
+}
+
+func (e *panicError) Unwrap() error {
+	return e.err
+}
+
+// StackTrace returns the stack of the panicking goroutine (refer to stackTracer).
+func (e *panicError) StackTrace() errors.StackTrace {
+	stack := make(errors.StackTrace, len(e.stack))
+	for i, pc := range e.stack {
+		stack[i] = errors.Frame(pc)
+	}
+	return stack
+}
+
+// panicStack returns the stack of the panicking goroutine from the function which panicked. It has to be called by
+// the deferred function recovering the panic, whose frames are followed by those of the panic.
+func panicStack() []uintptr {
+	var pcs [maxCallerDepth]uintptr
+	n := runtime.Callers(2, pcs[:]) // Skip runtime.Callers and panicStack
+
+	for i, pc := range pcs[:n] {
+		for _, info := range resolveCaller(pc) {
+			if info.frame.Function != "runtime.gopanic" {
+				continue
+			}
+
+			// Skip the runtime frames raising the panic (e.g. runtime.sigpanic for nil pointer dereferences)
+			for i++; i < n && resolveCaller(pcs[i])[0].pkg == "runtime"; i++ {
+			}
+			return pcs[i:n]
+		}
+	}
+	return pcs[:n] // Not called during a panic
+}
+
+// logRecovered logs the recovered panic value at panic level with the stack of the panicking goroutine and flushes
+// the telemetry. The panic raised by the backend after the entry is recovered, and the OpenTelemetry connection is
+// kept open since the program continues.
+func (lh *LogHelper) logRecovered(ctx context.Context, value interface{}) {
+	err, ok := value.(error)
+	if !ok {
+		err = stderrors.New(fmt.Sprint(value))
+	}
+	err = &panicError{err: err, stack: panicStack()}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	func() {
+		defer func() { _ = recover() }() // The backend panics after writing the entry
+		lh.WithError(context.WithValue(ctx, recoveredPanicKey{}, true), err).Panic(err)
+	}()
+
+	flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultFlushTimeout)
+	defer cancel()
+	if err := lh.Flush(flushCtx); err != nil {
+		lh.WithError(ctx, err).Warn("Failed to flush the telemetry after a panic")
+	}
+}
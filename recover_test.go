@@ -0,0 +1,91 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// recoverLogger returns a LogHelper writing JSON entries to the buffer.
+func recoverLogger(buf *bytes.Buffer) *LogHelper {
+	return NewLogHelper(WithOutput(buf), WithFormatter(&JSONFormatter{}), WithLevel(Debug))
+}
+
+//go:noinline
+func panicWith(value interface{}) {
+	panic(value)
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	var buf bytes.Buffer
+	lh := recoverLogger(&buf)
+
+	func() {
+		defer lh.RecoverAndLog(context.Background())
+		panicWith("boom")
+	}()
+
+	var entry struct {
+		Level string      `json:"level"`
+		Msg   string      `json:"msg"`
+		Error ErrorObject `json:"error"`
+		Stack string      `json:"stack"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid entry %q: %v", buf.String(), err)
+	}
+	if entry.Level != "panic" {
+		t.Errorf("level = %q, want panic", entry.Level)
+	}
+	if entry.Msg != "Recovered from a panic: boom" {
+		t.Errorf("msg = %q", entry.Msg)
+	}
+	if entry.Stack != "" {
+		t.Error("the separate stack field has not been replaced by the error object")
+	}
+	if len(entry.Error.Stack) == 0 || !strings.HasSuffix(entry.Error.Stack[0].Function, ".panicWith") {
+		t.Errorf("stack does not start at the panicking function: %+v", entry.Error.Stack)
+	}
+}
+
+func TestRecoverAndLogRepanic(t *testing.T) {
+	var buf bytes.Buffer
+	lh := recoverLogger(&buf)
+
+	value := func() (value interface{}) {
+		defer func() { value = recover() }()
+		defer lh.RecoverAndLogRepanic(context.Background())
+		panicWith("boom")
+		return nil
+	}()
+
+	if value != "boom" {
+		t.Errorf("repanicked with %v, want the recovered value", value)
+	}
+	if !strings.Contains(buf.String(), `"level":"panic"`) {
+		t.Errorf("panic has not been logged: %q", buf.String())
+	}
+}
+
+func TestRecoverAndLogNilPointer(t *testing.T) {
+	var buf bytes.Buffer
+	lh := recoverLogger(&buf)
+
+	func() {
+		defer lh.RecoverAndLog(context.Background())
+		var values map[string]*int
+		_ = *values["missing"]
+	}()
+
+	var entry struct {
+		Error ErrorObject `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid entry %q: %v", buf.String(), err)
+	}
+	if len(entry.Error.Stack) == 0 || !strings.Contains(entry.Error.Stack[0].Function, "TestRecoverAndLogNilPointer") {
+		t.Errorf("stack does not start at the panicking function: %+v", entry.Error.Stack)
+	}
+}
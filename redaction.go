@@ -0,0 +1,322 @@
+package FlowWatch
+
+import (
+	stderrors "errors"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultRedactionPlaceholder replaces the masked values.
+const DefaultRedactionPlaceholder = "[REDACTED]"
+
+// Patterns of common sensitive values, which can be added to the RedactionConfig.
+var (
+	CreditCardPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	EmailPattern      = regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)
+)
+
+// RedactionConfig configures the masking of sensitive values before the entries are formatted and added to spans.
+type RedactionConfig struct {
+	Fields      []string         // Names of the fields whose values are masked (case-insensitive)
+	Patterns    []*regexp.Regexp // Patterns masked in the messages, string values and error messages
+	Placeholder string           // Replacement of the masked values (default: DefaultRedactionPlaceholder)
+}
+
+// DefaultRedactionConfig returns a config masking credentials, credit card numbers and email addresses.
+func DefaultRedactionConfig() RedactionConfig {
+	return RedactionConfig{
+		Fields: []string{
+			"password", "passwd", "secret", "token", "access_token", "refresh_token", "authorization", "api_key",
+			"cookie",
+		},
+		Patterns: []*regexp.Regexp{CreditCardPattern, EmailPattern},
+	}
+}
+
+// redactor masks the values configured by a RedactionConfig.
+type redactor struct {
+	fields      map[string]bool
+	patterns    []*regexp.Regexp
+	placeholder string
+}
+
+// redactions counts the masked values of all LogHelper instances.
+var redactions atomic.Int64
+
+// Redactions returns the number of values masked since the start of the program, e.g. to detect code paths leaking
+// secrets into the logs.
+func Redactions() int64 {
+	return redactions.Load()
+}
+
+// SetRedaction masks the values of the configured fields and the matches of the patterns in all entries of the
+// LogHelper and its named loggers. An empty config disables the redaction.
+func (lh *LogHelper) SetRedaction(config RedactionConfig) {
+	if len(config.Fields) == 0 && len(config.Patterns) == 0 {
+		lh.root.redactor.Store(nil)
+		return
+	}
+
+	r := &redactor{
+		fields:      make(map[string]bool, len(config.Fields)),
+		patterns:    config.Patterns,
+		placeholder: config.Placeholder,
+	}
+	if r.placeholder == "" {
+		r.placeholder = DefaultRedactionPlaceholder
+	}
+	for _, field := range config.Fields {
+		r.fields[strings.ToLower(field)] = true
+	}
+	lh.root.redactor.Store(r)
+}
+
+// SetRedaction configures the redaction of the shared LogHelper instance (refer to LogHelper.SetRedaction).
+func SetRedaction(config RedactionConfig) {
+	GetLogHelper().SetRedaction(config)
+}
+
+// redact returns the fields and the message with the sensitive values masked. The fields are copied if a value is
+// masked, since they may be shared by an Entry.
+func (lh *LogHelper) redact(fields Fields, msg string) (Fields, string) {
+	r := lh.root.redactor.Load()
+	if r == nil {
+		return fields, msg
+	}
+
+	msg = r.redactString(msg)
+
+	copied := false
+	for key, value := range fields {
+		redacted, ok := r.redactValue(key, value)
+		if !ok {
+			continue
+		}
+		if !copied {
+			fields = copyFields(fields)
+			copied = true
+		}
+		fields[key] = redacted
+	}
+
+	return fields, msg
+}
+
+// maxRedactionDepth bounds the traversal of nested values (and of self-referencing pointers).
+const maxRedactionDepth = 8
+
+// redactValue returns the masked value and true if the value of the field is sensitive.
+func (r *redactor) redactValue(key string, value interface{}) (interface{}, bool) {
+	if r.fields[strings.ToLower(key)] {
+		redactions.Add(1)
+		return r.placeholder, true
+	}
+	return r.redactNested(reflect.ValueOf(value), 0)
+}
+
+// redactNested returns the masked value and true if the value contains a sensitive string, error message or field.
+// Maps, slices and structs containing sensitive values are converted into maps (with the JSON field names) and
+// slices of their masked elements, the other values are returned unchanged.
+func (r *redactor) redactNested(value reflect.Value, depth int) (interface{}, bool) {
+	if !value.IsValid() || !value.CanInterface() || depth > maxRedactionDepth {
+		return nil, false
+	}
+	if err, ok := value.Interface().(error); ok {
+		if value.Kind() == reflect.Pointer && value.IsNil() {
+			return nil, false
+		}
+		if message := err.Error(); r.matches(message) {
+			return &redactedError{err: err, message: r.redactString(message), redactor: r}, true
+		}
+		return nil, false
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		if s := value.String(); r.matches(s) {
+			return r.redactString(s), true
+		}
+	case reflect.Pointer, reflect.Interface:
+		if !value.IsNil() {
+			return r.redactNested(value.Elem(), depth+1)
+		}
+	case reflect.Map:
+		var masked map[string]interface{}
+		for iter := value.MapRange(); iter.Next(); {
+			key := fmt.Sprint(iter.Key().Interface())
+			if redacted, ok := r.redactEntry(key, iter.Value(), depth); ok {
+				if masked == nil {
+					masked = make(map[string]interface{}, value.Len())
+					for all := value.MapRange(); all.Next(); {
+						masked[fmt.Sprint(all.Key().Interface())] = interfaceOf(all.Value())
+					}
+				}
+				masked[key] = redacted
+			}
+		}
+		if masked != nil {
+			return masked, true
+		}
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return nil, false // Binary data
+		}
+		var masked []interface{}
+		for i := 0; i < value.Len(); i++ {
+			if redacted, ok := r.redactNested(value.Index(i), depth+1); ok {
+				if masked == nil {
+					masked = make([]interface{}, value.Len())
+					for j := range masked {
+						masked[j] = interfaceOf(value.Index(j))
+					}
+				}
+				masked[i] = redacted
+			}
+		}
+		if masked != nil {
+			return masked, true
+		}
+	case reflect.Struct:
+		var masked map[string]interface{}
+		for i := 0; i < value.NumField(); i++ {
+			name, ok := jsonFieldName(value.Type().Field(i))
+			if !ok {
+				continue
+			}
+			if redacted, ok := r.redactEntry(name, value.Field(i), depth); ok {
+				if masked == nil {
+					masked = structToMap(value)
+				}
+				masked[name] = redacted
+			}
+		}
+		if masked != nil {
+			return masked, true
+		}
+	}
+	return nil, false
+}
+
+// redactEntry returns the masked value and true if the name of the nested map entry or struct field is sensitive or
+// its value contains a sensitive value.
+func (r *redactor) redactEntry(name string, value reflect.Value, depth int) (interface{}, bool) {
+	if r.fields[strings.ToLower(name)] {
+		redactions.Add(1)
+		return r.placeholder, true
+	}
+	return r.redactNested(value, depth+1)
+}
+
+// matches reports whether one of the patterns matches the string.
+func (r *redactor) matches(s string) bool {
+	for _, pattern := range r.patterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString replaces the matches of the patterns with the placeholder and counts the replacements.
+func (r *redactor) redactString(s string) string {
+	s, replaced := r.mask(s)
+	redactions.Add(replaced)
+	return s
+}
+
+// mask replaces the matches of the patterns with the placeholder and returns the number of replacements.
+func (r *redactor) mask(s string) (string, int64) {
+	var replaced int64
+	for _, pattern := range r.patterns {
+		s = pattern.ReplaceAllStringFunc(s, func(string) string {
+			replaced++
+			return r.placeholder
+		})
+	}
+	return s, replaced
+}
+
+// redactedError masks the message of a sensitive error. The original error stays reachable for errors.Is, errors.As
+// and errors.Cause, so that decisions like the exit code of a fatal entry are unaffected by the redaction, while the
+// rendered messages (including those of the causes, refer to NewErrorObject) are masked.
+type redactedError struct {
+	err      error
+	message  string
+	redactor *redactor
+}
+
+// Error returns the masked message of the error.
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+// Unwrap returns the original error.
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// Cause returns the original error (used by errors.Cause of github.com/pkg/errors).
+func (e *redactedError) Cause() error {
+	return e.err
+}
+
+// StackTrace returns the stack trace of the deepest error in the chain of the original error.
+func (e *redactedError) StackTrace() errors.StackTrace {
+	var stack errors.StackTrace
+	for err := e.err; err != nil; err = stderrors.Unwrap(err) {
+		if tracer, ok := err.(stackTracer); ok {
+			stack = tracer.StackTrace()
+		}
+	}
+	return stack
+}
+
+// maskMessage masks a message derived from the original error, e.g. the message of a cause.
+func (e *redactedError) maskMessage(message string) string {
+	message, _ = e.redactor.mask(message)
+	return message
+}
+
+// jsonFieldName returns the JSON name of the struct field and false if the field is not serialized.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if !field.IsExported() || name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// structToMap converts the struct into a map with the JSON field names.
+func structToMap(value reflect.Value) map[string]interface{} {
+	converted := make(map[string]interface{}, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		if name, ok := jsonFieldName(value.Type().Field(i)); ok {
+			converted[name] = interfaceOf(value.Field(i))
+		}
+	}
+	return converted
+}
+
+// interfaceOf returns the value as interface or nil if it is invalid.
+func interfaceOf(value reflect.Value) interface{} {
+	if !value.IsValid() || !value.CanInterface() {
+		return nil
+	}
+	return value.Interface()
+}
+
+// copyFields returns a shallow copy of the fields.
+func copyFields(fields Fields) Fields {
+	copied := make(Fields, len(fields))
+	for key, value := range fields {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,111 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"testing"
+)
+
+// newRedactingLogHelper returns a LogHelper with the default redaction, which writes JSON entries to the buffer.
+func newRedactingLogHelper(out *bytes.Buffer) *LogHelper {
+	lh := NewLogHelper(WithHooks(), WithFormatter(&logrus.JSONFormatter{}), WithOutput(out))
+	lh.SetRedaction(DefaultRedactionConfig())
+	return lh
+}
+
+func TestRedactionFieldsAndMessage(t *testing.T) {
+	var out bytes.Buffer
+	lh := newRedactingLogHelper(&out)
+
+	before := Redactions()
+	lh.WithFields(context.Background(), Fields{
+		"password": "hunter2",
+		"Token":    "abc",
+		"user":     "alice",
+	}).Info("Signed in as alice@example.com")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("Invalid entry %q: %v", out.String(), err)
+	}
+	if entry["password"] != DefaultRedactionPlaceholder || entry["Token"] != DefaultRedactionPlaceholder {
+		t.Errorf("Sensitive fields not masked: %v", entry)
+	}
+	if entry["user"] != "alice" {
+		t.Errorf("Regular field masked: %v", entry["user"])
+	}
+	if msg := entry["msg"].(string); strings.Contains(msg, "alice@example.com") {
+		t.Errorf("Email address not masked in %q", msg)
+	}
+	if got := Redactions() - before; got != 3 {
+		t.Errorf("Counted %d redactions, want 3", got)
+	}
+}
+
+func TestRedactionNestedValues(t *testing.T) {
+	type credentials struct {
+		User   string `json:"user"`
+		Secret string `json:"secret"`
+	}
+
+	var out bytes.Buffer
+	lh := newRedactingLogHelper(&out)
+
+	lh.WithFields(context.Background(), Fields{
+		"request": map[string]interface{}{
+			"headers": map[string]string{"Authorization": "Bearer abc"},
+			"emails":  []string{"bob@example.com"},
+		},
+		"login": &credentials{User: "bob", Secret: "s3cr3t"},
+	}).Info("Request")
+
+	line := out.String()
+	for _, leaked := range []string{"Bearer abc", "bob@example.com", "s3cr3t"} {
+		if strings.Contains(line, leaked) {
+			t.Errorf("Nested value %q not masked in %s", leaked, line)
+		}
+	}
+	if !strings.Contains(line, `"user":"bob"`) {
+		t.Errorf("Regular nested value masked in %s", line)
+	}
+}
+
+func TestRedactionKeepsErrorChain(t *testing.T) {
+	var out bytes.Buffer
+	lh := newRedactingLogHelper(&out)
+
+	cause := errors.New("Lookup of carol@example.com failed")
+	err := errors.Wrap(cause, "Failed to load the profile")
+
+	fields, _ := lh.redact(Fields{ErrorKey: err}, "")
+	redacted, ok := fields[ErrorKey].(error)
+	if !ok {
+		t.Fatalf("Redacted error has type %T", fields[ErrorKey])
+	}
+	if strings.Contains(redacted.Error(), "carol@example.com") {
+		t.Errorf("Email address not masked in %q", redacted.Error())
+	}
+	if !errors.Is(redacted, cause) || errors.Cause(redacted) != cause {
+		t.Error("Redacted error does not keep the chain")
+	}
+	for _, object := range NewErrorObject(redacted).Causes {
+		if strings.Contains(object.Message, "carol@example.com") {
+			t.Errorf("Email address not masked in the cause %q", object.Message)
+		}
+	}
+}
+
+func TestRedactionDisabled(t *testing.T) {
+	var out bytes.Buffer
+	lh := newRedactingLogHelper(&out)
+	lh.SetRedaction(RedactionConfig{})
+
+	lh.WithField(context.Background(), "password", "hunter2").Info("Signed in")
+	if !strings.Contains(out.String(), "hunter2") {
+		t.Errorf("Value masked with disabled redaction: %s", out.String())
+	}
+}
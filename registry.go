@@ -0,0 +1,91 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sinkShutdownBudget is the time a sink created from FLOWWATCH_SINKS gets to deliver its remaining entries.
+const sinkShutdownBudget = 2 * time.Second
+
+// UnknownSinkError is returned if a sink is selected that has not been registered.
+var UnknownSinkError = errors.New("Unknown sink")
+
+// SinkFactory creates the hook of a sink selected by name. Hooks implementing Close(context.Context) error are closed
+// on the shutdown of OpenTelemetry.
+type SinkFactory func() (logrus.Hook, error)
+
+var (
+	registryMu sync.RWMutex
+	formatters = make(map[string]logrus.Formatter)
+	sinks      = make(map[string]SinkFactory)
+)
+
+// RegisterFormatter makes the formatter selectable by name with FLOWWATCH_FORMAT, so that proprietary formats can be
+// plugged in without forking FlowWatch. Registered formatters take precedence over the built-in formats.
+func RegisterFormatter(name string, formatter logrus.Formatter) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	formatters[strings.ToLower(name)] = formatter
+}
+
+// RegisterSink makes the sink selectable by name with FLOWWATCH_SINKS (a comma separated list of names), so that
+// proprietary destinations can be plugged in without forking FlowWatch.
+func RegisterSink(name string, factory SinkFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	sinks[strings.ToLower(name)] = factory
+}
+
+// registeredFormatter returns the formatter registered under the name.
+func registeredFormatter(name string) (logrus.Formatter, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	formatter, ok := formatters[strings.ToLower(name)]
+	return formatter, ok
+}
+
+// newSink creates the hook of the sink registered under the name.
+func newSink(name string) (logrus.Hook, error) {
+	registryMu.RLock()
+	factory, ok := sinks[strings.ToLower(name)]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, errors.Wrapf(UnknownSinkError, "Sink %q", name)
+	}
+	hook, err := factory()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to create the sink %q", name)
+	}
+	return hook, nil
+}
+
+// addSinksFromEnv adds the sinks selected by FLOWWATCH_SINKS (if set) and logs the sinks that cannot be created.
+func (lh *LogHelper) addSinksFromEnv(names string) {
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		hook, err := newSink(name)
+		if err != nil {
+			lh.WithError(context.Background(), err).Warn("Failed to add a sink of FLOWWATCH_SINKS, ignoring it")
+			continue
+		}
+		lh.addHook(hook)
+
+		if closer, ok := hook.(interface{ Close(context.Context) error }); ok {
+			otelHelper.RegisterShutdown(name+" sink", sinkShutdownBudget, closer.Close)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package FlowWatch
+
+import (
+	"compress/gzip"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxFileSize is the default size in bytes at which a RotatingFile is rotated (100 MiB).
+const DefaultMaxFileSize = 100 * 1024 * 1024
+
+// backupTimeFormat is the format of the timestamp in the names of the rotated files (sortable and valid on Windows).
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// FileConfig configures a RotatingFile.
+type FileConfig struct {
+	Path             string        // Path of the log file, e.g. /var/log/orders/orders.log
+	MaxSize          int64         // Size in bytes at which the file is rotated (default: DefaultMaxFileSize)
+	RotationInterval time.Duration // Interval after which the file is rotated regardless of its size (0 disables it)
+	MaxAge           time.Duration // Age after which rotated files are deleted (0 keeps them)
+	MaxBackups       int           // Number of rotated files which are kept (0 keeps all)
+	Compress         bool          // Whether the rotated files are compressed with gzip
+}
+
+// RotatingFile is an io.Writer for log files, which rotates the file if it exceeds the maximum size or the rotation
+// interval has elapsed and deletes old rotated files, so that deployments without a log shipper do not fill the disk.
+// The rotated files are named after the file with the rotation time, e.g. orders-2025-01-01T12-00-00.000.log.
+type RotatingFile struct {
+	config FileConfig
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	cleanupMu sync.Mutex     // Serializes the compression and deletion of the rotated files
+	cleanups  sync.WaitGroup // Running compressions and deletions
+}
+
+// NewRotatingFile creates a RotatingFile for the config. The file is opened with the first write.
+func NewRotatingFile(config FileConfig) *RotatingFile {
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultMaxFileSize
+	}
+	return &RotatingFile{config: config}
+}
+
+// WithRotatingFile writes the entries to a RotatingFile configured by the config (refer to WithOutput).
+func WithRotatingFile(config FileConfig) Option {
+	return WithOutput(NewRotatingFile(config))
+}
+
+// Write writes the entry to the file and rotates it beforehand if necessary.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	expired := f.config.RotationInterval > 0 && time.Since(f.openedAt) >= f.config.RotationInterval
+	if f.size > 0 && (f.size+int64(len(p)) > f.config.MaxSize || expired) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+// Sync commits the content of the file to the disk.
+func (f *RotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close closes the file and waits for the running compressions of rotated files.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	f.cleanups.Wait()
+
+	return err
+}
+
+// open opens or creates the log file (including its directory) for appending.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.config.Path), 0o755); err != nil {
+		return errors.Wrap(err, "Failed to create the log directory")
+	}
+
+	file, err := os.OpenFile(f.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return errors.Wrap(err, "Failed to open the log file")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, "Failed to stat the log file")
+	}
+
+	f.file, f.size, f.openedAt = file, info.Size(), time.Now()
+	return nil
+}
+
+// rotate renames the current file, opens a new one and cleans up the rotated files in the background.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return errors.Wrap(err, "Failed to close the log file")
+	}
+	f.file = nil
+
+	backup := f.backupName(time.Now())
+	if err := os.Rename(f.config.Path, backup); err != nil {
+		return errors.Wrap(err, "Failed to rename the log file")
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.cleanups.Add(1)
+	go func() {
+		defer f.cleanups.Done()
+		f.cleanup(backup)
+	}()
+
+	return nil
+}
+
+// backupName returns the name of the rotated file for the rotation time.
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.config.Path)
+	return strings.TrimSuffix(f.config.Path, ext) + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// isBackupName reports whether the file is a rotated file of the RotatingFile (optionally compressed), and not a
+// sibling file sharing the prefix like app-audit.log of app.log.
+func (f *RotatingFile) isBackupName(name string) bool {
+	ext := filepath.Ext(f.config.Path)
+	timestamp, ok := strings.CutPrefix(name, strings.TrimSuffix(f.config.Path, ext)+"-")
+	if !ok {
+		return false
+	}
+	timestamp, ok = strings.CutSuffix(strings.TrimSuffix(timestamp, ".gz"), ext)
+	if !ok {
+		return false
+	}
+
+	_, err := time.Parse(backupTimeFormat, timestamp)
+	return err == nil
+}
+
+// cleanup compresses the rotated file if configured and deletes the rotated files exceeding the limits. Failures are
+// ignored, since they cannot be logged without risking a loop.
+func (f *RotatingFile) cleanup(backup string) {
+	f.cleanupMu.Lock()
+	defer f.cleanupMu.Unlock()
+
+	if f.config.Compress {
+		if err := compressFile(backup); err == nil {
+			_ = os.Remove(backup)
+		}
+	}
+
+	if f.config.MaxBackups <= 0 && f.config.MaxAge <= 0 {
+		return
+	}
+
+	// List the rotated files from the newest to the oldest (the names are sortable by the rotation time)
+	ext := filepath.Ext(f.config.Path)
+	pattern := strings.TrimSuffix(f.config.Path, ext) + "-*" + ext + "*"
+	matches, _ := filepath.Glob(pattern)
+	backups := matches[:0]
+	for _, name := range matches {
+		if f.isBackupName(name) {
+			backups = append(backups, name)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	for i, name := range backups {
+		info, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		tooMany := f.config.MaxBackups > 0 && i >= f.config.MaxBackups
+		tooOld := f.config.MaxAge > 0 && time.Since(info.ModTime()) > f.config.MaxAge
+		if tooMany || tooOld {
+			_ = os.Remove(name)
+		}
+	}
+}
+
+// compressFile writes the gzip compressed content of the file to the file with the suffix .gz.
+func compressFile(name string) error {
+	source, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(target)
+	_, err = io.Copy(writer, source)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := target.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(name + ".gz")
+	}
+
+	return err
+}
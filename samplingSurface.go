@@ -0,0 +1,29 @@
+package FlowWatch
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"sync/atomic"
+)
+
+// SpanExportedKey is the key of the field which marks entries whose span event is not exported.
+const SpanExportedKey = "span_exported"
+
+var markUnexported atomic.Bool
+
+// SetMarkUnexportedEvents enables adding the "span_exported" field (false) to entries at warning level and higher
+// whose span event is not exported, because there is no span in the context or the span is not sampled.
+func SetMarkUnexportedEvents(enabled bool) {
+	markUnexported.Store(enabled)
+}
+
+// markUnexportedEvent adds the "span_exported" field to the data if the event is not exported and marking is enabled.
+func markUnexportedEvent(ctx context.Context, data *entryFields) {
+	if !markUnexported.Load() {
+		return
+	}
+
+	if !trace.SpanContextFromContext(ctx).IsSampled() {
+		data.set(SpanExportedKey, false)
+	}
+}
@@ -0,0 +1,326 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"context"
+	cryptoRand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Limits of the Sentry batches (every event is sent as separate envelope).
+const (
+	sentryMaxEvents     = 100
+	sentryMaxBatchBytes = 1024 * 1024
+)
+
+var (
+	// InvalidSentryDSNError is returned if the DSN of the SentryConfig cannot be parsed.
+	InvalidSentryDSNError = errors.New("Invalid Sentry DSN")
+
+	// SentryRequestError is returned if Sentry rejected an event.
+	SentryRequestError = errors.New("Sentry request failed")
+)
+
+// SentryConfig configures the LogrusSentryHook.
+type SentryConfig struct {
+	DSN           string         // DSN of the Sentry project, e.g. https://<key>@o0.ingest.sentry.io/<project>
+	Environment   string         // Environment tag of the events (default: ENV)
+	Release       string         // Release tag of the events
+	SampleRate    float64        // Share of the events sent (default: 1)
+	MinLevel      *Level         // Least severe level forwarded (default: Error)
+	FlushInterval time.Duration  // Interval after which the events are sent (default: DefaultBatchInterval)
+	MaxRetries    int            // Retries of failed requests (default: DefaultBatchRetries, < 0 disables them)
+	MaxBuffered   int            // Entries buffered while the service is unreachable (default: DefaultBatchBufferedEvents)
+	Client        *http.Client   // HTTP client (default: a client with a timeout of 10 seconds)
+	serverName    string         // Host name reported as server_name
+	endpoint      string         // Envelope endpoint derived from the DSN
+	auth          string         // X-Sentry-Auth header derived from the DSN
+	levels        []logrus.Level // Levels derived from MinLevel
+}
+
+// sentryEvent is the subset of the Sentry event payload written by the LogrusSentryHook.
+type sentryEvent struct {
+	EventID     string                    `json:"event_id"`
+	Timestamp   string                    `json:"timestamp"`
+	Level       string                    `json:"level"`
+	Logger      string                    `json:"logger,omitempty"`
+	Platform    string                    `json:"platform"`
+	ServerName  string                    `json:"server_name,omitempty"`
+	Environment string                    `json:"environment,omitempty"`
+	Release     string                    `json:"release,omitempty"`
+	Message     *sentryMessage            `json:"message,omitempty"`
+	Exception   []sentryException         `json:"exception,omitempty"`
+	Extra       map[string]interface{}    `json:"extra,omitempty"`
+	Contexts    map[string]sentryTraceCtx `json:"contexts,omitempty"`
+}
+
+// sentryMessage is the message interface of a Sentry event.
+type sentryMessage struct {
+	Formatted string `json:"formatted"`
+}
+
+// sentryException is an exception of a Sentry event with the stack trace of the error or the log call.
+type sentryException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value"`
+	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+}
+
+// sentryStacktrace contains the frames of an exception ordered from the outermost to the innermost call.
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+// sentryFrame is a frame of a stack trace.
+type sentryFrame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// sentryTraceCtx is the trace context correlating the event with the OpenTelemetry trace.
+type sentryTraceCtx struct {
+	TraceID string `json:"trace_id"`
+	SpanID  string `json:"span_id"`
+}
+
+// sentryLevels maps the logrus levels to the levels of Sentry.
+var sentryLevels = map[logrus.Level]string{
+	logrus.TraceLevel: "debug",
+	logrus.DebugLevel: "debug",
+	logrus.InfoLevel:  "info",
+	logrus.WarnLevel:  "warning",
+	logrus.ErrorLevel: "error",
+	logrus.FatalLevel: "fatal",
+	logrus.PanicLevel: "fatal",
+}
+
+// LogrusSentryHook is a hook for logrus that forwards error entries to Sentry with their stack trace, the fields as
+// extras and the trace context, so that exception-centric alerting works alongside OpenTelemetry. The events are sent
+// from a background goroutine and retried with backoff (Sentry deduplicates retried events by their ID).
+type LogrusSentryHook struct {
+	config  SentryConfig
+	batcher *batcher
+}
+
+// NewSentryHook creates a hook forwarding the entries to the Sentry project of the DSN.
+func NewSentryHook(config SentryConfig) (*LogrusSentryHook, error) {
+	dsn, err := url.Parse(config.DSN)
+	if err != nil || dsn.User == nil || dsn.Host == "" {
+		return nil, errors.Wrapf(InvalidSentryDSNError, "Failed to parse %q", config.DSN)
+	}
+	projectPath, projectID := "", strings.Trim(dsn.Path, "/")
+	if i := strings.LastIndex(projectID, "/"); i >= 0 {
+		projectPath, projectID = "/"+projectID[:i], projectID[i+1:]
+	}
+	if projectID == "" {
+		return nil, errors.Wrapf(InvalidSentryDSNError, "Missing project in %q", config.DSN)
+	}
+	config.endpoint = fmt.Sprintf("%s://%s%s/api/%s/envelope/", dsn.Scheme, dsn.Host, projectPath, projectID)
+	config.auth = fmt.Sprintf("Sentry sentry_version=7, sentry_client=flowwatch, sentry_key=%s",
+		dsn.User.Username())
+
+	if config.Environment == "" {
+		config.Environment = otelHelper.Getenv("ENV")
+	}
+	if config.SampleRate <= 0 {
+		config.SampleRate = 1
+	}
+	minLevel := Error
+	if config.MinLevel != nil {
+		minLevel = *config.MinLevel
+	}
+	for _, level := range logrus.AllLevels {
+		if levelFromLogrus(level).AtLeast(minLevel) {
+			config.levels = append(config.levels, level)
+		}
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = DefaultBatchRetries
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	config.serverName, _ = os.Hostname()
+
+	hook := &LogrusSentryHook{config: config}
+	hook.batcher = newBatcher(batchConfig{
+		maxEvents: sentryMaxEvents,
+		maxBytes:  sentryMaxBatchBytes,
+		interval:  config.FlushInterval,
+		retries:   config.MaxRetries,
+
+		maxBufferedEvents: config.MaxBuffered,
+	}, hook.send)
+
+	return hook, nil
+}
+
+// Levels returns all log levels for which the LogrusSentryHook should be activated (MinLevel and above).
+func (hook *LogrusSentryHook) Levels() []logrus.Level {
+	return hook.config.levels
+}
+
+// Fire is called when the LogrusSentryHook is activated (when a log entry is made).
+func (hook *LogrusSentryHook) Fire(entry *logrus.Entry) error {
+	if hook.config.SampleRate < 1 && rand.Float64() >= hook.config.SampleRate {
+		return nil
+	}
+
+	event := hook.event(entry)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal the Sentry event")
+	}
+
+	// The envelope consists of the envelope header, the item header and the event, each on its own line
+	line := fmt.Sprintf("{\"event_id\":%q}\n{\"type\":\"event\",\"length\":%d}\n%s\n", event.EventID, len(payload),
+		payload)
+	hook.batcher.add(batchEvent{time: entry.Time, level: levelFromLogrus(entry.Level), line: line})
+
+	return nil
+}
+
+// Flush sends the collected events.
+func (hook *LogrusSentryHook) Flush(ctx context.Context) error {
+	return hook.batcher.Flush(ctx)
+}
+
+// Close sends the remaining events and stops the background goroutine.
+func (hook *LogrusSentryHook) Close(ctx context.Context) error {
+	return hook.batcher.Close(ctx)
+}
+
+// event converts the entry into a Sentry event.
+func (hook *LogrusSentryHook) event(entry *logrus.Entry) sentryEvent {
+	var id [16]byte
+	_, _ = cryptoRand.Read(id[:])
+
+	event := sentryEvent{
+		EventID:     hex.EncodeToString(id[:]),
+		Timestamp:   entry.Time.UTC().Format(time.RFC3339Nano),
+		Level:       sentryLevels[entry.Level],
+		Platform:    "go",
+		ServerName:  hook.config.serverName,
+		Environment: hook.config.Environment,
+		Release:     hook.config.Release,
+		Message:     &sentryMessage{Formatted: entry.Message},
+		Extra:       make(map[string]interface{}, len(entry.Data)),
+	}
+
+	for key, value := range entry.Data {
+		switch key {
+		case ErrorKey, StackKey:
+			continue // Sent as exception
+		case TraceIDKey, SpanIDKey:
+			continue // Sent as trace context
+		case LoggerKey:
+			event.Logger = fmt.Sprint(value)
+		default:
+			if err, ok := value.(error); ok {
+				value = err.Error() // Errors are not serialized by encoding/json
+			}
+			event.Extra[key] = value
+		}
+	}
+
+	exception := sentryException{Type: "log", Value: entry.Message}
+	var pcs []uintptr
+	if err, ok := entry.Data[ErrorKey].(error); ok {
+		exception.Type = fmt.Sprintf("%T", errors.Cause(err))
+		exception.Value = err.Error()
+		if tracer, ok := err.(stackTracer); ok {
+			for _, frame := range tracer.StackTrace() {
+				pcs = append(pcs, uintptr(frame))
+			}
+		}
+	}
+	if pcs == nil {
+		// Use the stack of the log call if the error does not carry one
+		var callers [maxCallerDepth]uintptr
+		pcs = callers[:runtime.Callers(2, callers[:])]
+	}
+	exception.Stacktrace = sentryFrames(pcs)
+	event.Exception = []sentryException{exception}
+
+	if entry.Context != nil {
+		if spanContext := trace.SpanContextFromContext(entry.Context); spanContext.IsValid() {
+			event.Contexts = map[string]sentryTraceCtx{"trace": {
+				TraceID: spanContext.TraceID().String(),
+				SpanID:  spanContext.SpanID().String(),
+			}}
+		}
+	}
+
+	return event
+}
+
+// sentryFrames converts the program counters into a stack trace, omitting the frames of the logging packages.
+func sentryFrames(pcs []uintptr) *sentryStacktrace {
+	var frames []sentryFrame
+	for _, pc := range pcs {
+		for _, info := range resolveCaller(pc) {
+			if info.logging || info.frame.Function == "" {
+				continue
+			}
+			frames = append(frames, sentryFrame{
+				Function: info.frame.Function,
+				Module:   info.pkg,
+				AbsPath:  info.frame.File,
+				Lineno:   info.frame.Line,
+				InApp:    strings.Contains(strings.SplitN(info.pkg, "/", 2)[0], "."), // The standard library is not
+			})
+		}
+	}
+	if len(frames) == 0 {
+		return nil
+	}
+
+	// Sentry expects the innermost call last
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return &sentryStacktrace{Frames: frames}
+}
+
+// send posts the events of the batch, each as separate envelope.
+func (hook *LogrusSentryHook) send(ctx context.Context, events []batchEvent) error {
+	for _, event := range events {
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.config.endpoint,
+			bytes.NewReader([]byte(event.line)))
+		if err != nil {
+			return errors.Wrap(err, "Failed to create the Sentry request")
+		}
+		request.Header.Set("Content-Type", "application/x-sentry-envelope")
+		request.Header.Set("X-Sentry-Auth", hook.config.auth)
+
+		response, err := hook.config.Client.Do(request)
+		if err != nil {
+			return errors.Wrap(err, "Failed to send to Sentry")
+		}
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		_ = response.Body.Close()
+
+		if response.StatusCode/100 != 2 {
+			return errors.Wrapf(SentryRequestError, "Status %d: %s", response.StatusCode,
+				strings.TrimSpace(string(message)))
+		}
+	}
+	return nil
+}
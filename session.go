@@ -0,0 +1,116 @@
+package FlowWatch
+
+import (
+	"context"
+	"fmt"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"time"
+)
+
+// redactedValue replaces the values of sensitive command line arguments.
+const redactedValue = "[REDACTED]"
+
+// sensitiveArgNames are the parts of flag names whose values are redacted in the recorded arguments.
+var sensitiveArgNames = []string{"password", "passwd", "secret", "token", "key", "credential", "auth"}
+
+// Session is the logging context of a single invocation of a CLI tool with a root span covering the invocation.
+type Session struct {
+	lh    *LogHelper
+	ctx   context.Context
+	span  trace.Span
+	start time.Time
+}
+
+// StartSession sets up FlowWatch for a CLI tool: entries of the LogHelper are written by the ConsoleFormatter unless
+// FLOWWATCH_FORMAT selects a format, a root span named after the tool is started and the redacted arguments are
+// recorded on it. The spans are only exported if OTEL_COLLECTOR_URL is set. The returned context carries the root span.
+func (lh *LogHelper) StartSession(ctx context.Context, name string, args []string) (context.Context, *Session) {
+	otelHelper.SetupOtelHelper()
+
+	backend, ok := lh.Backend().(*LogrusBackend)
+	if ok && otelHelper.Getenv("FLOWWATCH_FORMAT") == "" {
+		backend.Logger.SetFormatter(&ConsoleFormatter{
+			DisableColors:   !isTerminal(backend.Logger.Out),
+			TimestampFormat: time.TimeOnly,
+		})
+	}
+
+	ctx, span := tracer.Start(ctx, name, trace.WithNewRoot(), trace.WithAttributes(
+		attribute.String("process.executable.name", name),
+		attribute.StringSlice("process.command_args", redactArgs(args)),
+	))
+
+	return ctx, &Session{lh: lh, ctx: ctx, span: span, start: time.Now()}
+}
+
+// StartSession starts a session with the shared LogHelper instance (refer to LogHelper.StartSession).
+func StartSession(ctx context.Context, name string, args []string) (context.Context, *Session) {
+	return GetLogHelper().StartSession(ctx, name, args)
+}
+
+// End records the exit code on the root span, ends it and flushes the telemetry. A non-zero exit code marks the span
+// as failed.
+func (s *Session) End(exitCode int) {
+	elapsed := time.Since(s.start)
+
+	s.span.SetAttributes(attribute.Int("process.exit.code", exitCode))
+	if exitCode != 0 {
+		s.span.SetStatus(codes.Error, fmt.Sprintf("Exited with code %d", exitCode))
+	}
+	s.lh.WithFields(s.ctx, Fields{
+		"exit_code":  exitCode,
+		"elapsed_ms": elapsed.Milliseconds(),
+	}).Debug("Session finished")
+	s.span.End()
+
+	flushCtx, cancel := context.WithTimeout(context.WithoutCancel(s.ctx), defaultFlushTimeout)
+	defer cancel()
+	if err := s.lh.Flush(flushCtx); err != nil {
+		s.lh.WithError(s.ctx, err).Warn("Failed to flush the telemetry of the session")
+	}
+}
+
+// Exit ends the session and terminates the program with the exit code using the exit function of the LogHelper
+// (refer to WithExitFunc).
+func (s *Session) Exit(exitCode int) {
+	s.End(exitCode)
+	s.lh.exitFunc(exitCode)
+}
+
+// redactArgs returns a copy of the arguments with the values of sensitive flags (e.g. --token=abc or --password abc)
+// replaced.
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+
+	for i := 0; i < len(redacted); i++ {
+		arg := redacted[i]
+		if !strings.HasPrefix(arg, "-") || !isSensitiveArg(arg) {
+			continue
+		}
+
+		if name, _, ok := strings.Cut(arg, "="); ok {
+			redacted[i] = name + "=" + redactedValue
+		} else if i+1 < len(redacted) && !strings.HasPrefix(redacted[i+1], "-") {
+			redacted[i+1] = redactedValue
+			i++
+		}
+	}
+
+	return redacted
+}
+
+// isSensitiveArg reports whether the flag name of the argument contains one of the sensitiveArgNames.
+func isSensitiveArg(arg string) bool {
+	name, _, _ := strings.Cut(strings.ToLower(strings.TrimLeft(arg, "-")), "=")
+	for _, sensitive := range sensitiveArgNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
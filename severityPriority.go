@@ -0,0 +1,29 @@
+package FlowWatch
+
+import (
+	"context"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"sync/atomic"
+)
+
+// severityPriorityLevel is the level from which entries raise the sampling priority of their trace (nil if disabled).
+var severityPriorityLevel atomic.Pointer[Level]
+
+// EnableSeverityPriority raises the sampling priority of traces which produce entries at the level (warning or
+// higher) or above, so that the traces users need to debug are retained: the span is marked for tail-based samplers
+// and the spans of the trace started afterward in this process are sampled (refer to otelHelper.RaiseSamplingPriority).
+func EnableSeverityPriority(level Level) {
+	severityPriorityLevel.Store(&level)
+}
+
+// DisableSeverityPriority stops raising the sampling priority of traces based on the level of their entries.
+func DisableSeverityPriority() {
+	severityPriorityLevel.Store(nil)
+}
+
+// raiseSeverityPriority raises the sampling priority of the trace from the context if the level is severe enough.
+func raiseSeverityPriority(ctx context.Context, level Level) {
+	if threshold := severityPriorityLevel.Load(); threshold != nil && level.AtLeast(*threshold) && ctx != nil {
+		otelHelper.RaiseSamplingPriority(ctx)
+	}
+}
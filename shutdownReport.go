@@ -0,0 +1,57 @@
+package FlowWatch
+
+import (
+	"context"
+	"fmt"
+	"github.com/LucaSchmitz2003/FlowWatch/otelHelper"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// shutdownReportRoutes is the number of slowest routes in the shutdown report.
+const shutdownReportRoutes = 5
+
+// levelCounts counts the written entries of all LogHelper instances by the value of their level.
+var levelCounts [Trace + 1]atomic.Int64
+
+func init() {
+	otelHelper.OnShutdown(logShutdownReport)
+}
+
+// countEntry counts a written entry for the shutdown report.
+func countEntry(level Level) {
+	if level <= Trace {
+		levelCounts[level].Add(1)
+	}
+}
+
+// logShutdownReport logs a final entry summarizing the process lifetime (uptime, entries per level, exported and
+// failed spans, exported and dropped log records, dropped sink entries and the slowest routes) as operational
+// fingerprint for postmortems and capacity reviews.
+func logShutdownReport() {
+	stats := otelHelper.GetExportStats(shutdownReportRoutes)
+	logStats := otelHelper.GetLogExportStats()
+
+	fields := Fields{
+		"uptime_s":       int64(time.Since(processStart).Seconds()),
+		"spans_exported": stats.SpansExported,
+		"spans_failed":   stats.SpansFailed, // The queue drops of the batch span processor are not exposed by the SDK
+		"logs_exported":  logStats.RecordsExported,
+		"logs_dropped":   logStats.RecordsFailed + logStats.RecordsDropped,
+		"sinks_dropped":  SinkEventsDropped(),
+	}
+	for _, level := range allLevels {
+		fields["entries_"+strings.ToLower(level.String())] = levelCounts[level].Load()
+	}
+
+	routes := make([]string, 0, len(stats.SlowestRoutes))
+	for _, route := range stats.SlowestRoutes {
+		routes = append(routes, fmt.Sprintf("%s=%s", route.Name, route.Max.Round(time.Millisecond)))
+	}
+	if len(routes) > 0 {
+		fields["slowest_routes"] = strings.Join(routes, ", ")
+	}
+
+	GetLogHelper().WithFields(context.Background(), fields).Info("Shutdown report")
+}
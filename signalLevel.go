@@ -0,0 +1,54 @@
+//go:build !windows
+
+package FlowWatch
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+)
+
+// EnableSignalLevelToggling lets operators change the log level of the shared LogHelper instance with signals:
+// SIGUSR1 raises the verbosity by one level (e.g. Info to Debug) and SIGUSR2 lowers it. Every change is logged.
+// The returned function stops the handling of the signals.
+func EnableSignalLevelToggling() (stop func()) {
+	lh := GetLogHelper()
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				toggleLevel(lh, sig == syscall.SIGUSR1)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// toggleLevel raises or lowers the verbosity of the logger by one level and logs the change.
+func toggleLevel(lh *LogHelper, verbose bool) {
+	previous := lh.GetLevel()
+	index := slices.Index(allLevels, previous)
+	if verbose && index > 0 {
+		index--
+	} else if !verbose && previous.Severity() < Fatal.Severity() {
+		index++
+	}
+
+	lh.SetLevel(allLevels[index])
+	lh.WithFields(context.Background(), Fields{
+		"previous_level": previous.String(),
+		"new_level":      lh.GetLevel().String(),
+	}).Warn("Log level changed by signal")
+}
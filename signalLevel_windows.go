@@ -0,0 +1,7 @@
+package FlowWatch
+
+// EnableSignalLevelToggling is not supported on Windows, since it lacks SIGUSR1 and SIGUSR2. The returned function
+// does nothing.
+func EnableSignalLevelToggling() (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,65 @@
+package FlowWatch
+
+import (
+	"github.com/pkg/errors"
+	"net"
+	"time"
+)
+
+// Timings of the socket sinks (GELF and syslog).
+const (
+	sinkSendInterval        = time.Second     // Interval after which the buffered entries are sent at the latest
+	sinkWriteTimeout        = 5 * time.Second // Deadline of a single write
+	sinkMinReconnectBackoff = time.Second     // Delay before the first reconnect after a failed dial
+	sinkMaxReconnectBackoff = time.Minute     // Upper bound of the reconnect delay, doubled after every failed dial
+)
+
+// SinkReconnectBackoffError is returned if a socket sink waits before it reconnects to an unreachable server.
+var SinkReconnectBackoffError = errors.New("Waiting to reconnect")
+
+// sinkConn is the connection of a socket sink. It is dialed lazily, bounds every write with a deadline and backs off
+// the reconnects while the server is unreachable. It is only used by the background goroutine of the batcher of the
+// sink (and by Close afterward), so it is not synchronized.
+type sinkConn struct {
+	dial func() (net.Conn, error)
+
+	conn    net.Conn
+	backoff time.Duration
+	retryAt time.Time // Time before which no reconnect is attempted
+}
+
+// write writes the payload with a deadline, establishing the connection first if needed. The connection is closed if
+// the write fails, so that the next write reconnects.
+func (c *sinkConn) write(payload []byte) error {
+	if c.conn == nil {
+		if wait := time.Until(c.retryAt); wait > 0 {
+			return errors.Wrapf(SinkReconnectBackoffError, "Retrying in %s", wait.Round(time.Millisecond))
+		}
+
+		conn, err := c.dial()
+		if err != nil {
+			c.backoff = min(max(2*c.backoff, sinkMinReconnectBackoff), sinkMaxReconnectBackoff)
+			c.retryAt = time.Now().Add(c.backoff)
+			return err
+		}
+		c.conn, c.backoff = conn, 0
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(sinkWriteTimeout))
+	if _, err := c.conn.Write(payload); err != nil {
+		_ = c.Close()
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection.
+func (c *sinkConn) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+
+	return err
+}
@@ -0,0 +1,96 @@
+package FlowWatch
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"sync/atomic"
+)
+
+// DefaultMaxEntrySize is the default maximum size of a log entry in bytes (message and field values).
+const DefaultMaxEntrySize = 1024 * 1024
+
+// truncationSuffix is appended to truncated values to make the truncation visible in the output.
+const truncationSuffix = "...(truncated)"
+
+var maxEntrySize atomic.Int64
+
+func init() {
+	maxEntrySize.Store(DefaultMaxEntrySize)
+}
+
+// SetMaxEntrySize updates the maximum size of a log entry in bytes. A size of zero or less disables the size guard.
+func SetMaxEntrySize(size int) {
+	maxEntrySize.Store(int64(size))
+}
+
+// LogrusSizeGuardHook is a hook for logrus that enforces the maximum entry size by truncating the largest fields first.
+// The keys of the truncated fields are added to the entry as "truncated_fields".
+type LogrusSizeGuardHook struct{}
+
+// Levels returns all log levels for which the LogrusSizeGuardHook should be activated (all levels).
+func (hook LogrusSizeGuardHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire is called when the LogrusSizeGuardHook is activated (when a log entry is made).
+func (hook LogrusSizeGuardHook) Fire(entry *logrus.Entry) error {
+	entry.Message = guardEntrySize(hookFields(entry), entry.Message)
+	return nil
+}
+
+// guardEntrySize truncates the largest fields and, if that is not sufficient, the message until the entry fits into
+// the maximum entry size. It returns the message, which may have been truncated.
+func guardEntrySize(data *entryFields, msg string) string {
+	limit := int(maxEntrySize.Load())
+	if limit <= 0 {
+		return msg
+	}
+
+	// Determine the size of the message and of all field values
+	total := len(msg)
+	values := make(map[string]string, len(data.data))
+	keys := make([]string, 0, len(data.data))
+	for key, value := range data.data {
+		values[key] = fmt.Sprint(value)
+		keys = append(keys, key)
+		total += len(key) + len(values[key])
+	}
+
+	if total <= limit {
+		return msg
+	}
+
+	// Truncate the largest fields first until the entry fits
+	sort.Slice(keys, func(i, j int) bool {
+		return len(values[keys[i]]) > len(values[keys[j]])
+	})
+
+	var truncated []string
+	for _, key := range keys {
+		if total <= limit {
+			break
+		}
+		value := values[key]
+		if len(value) <= len(truncationSuffix) {
+			break // The remaining fields are too small to gain anything from truncating them
+		}
+
+		keep := max(len(value)-(total-limit)-len(truncationSuffix), 0)
+		cut := cutMessage(value, keep) + truncationSuffix
+		data.set(key, cut)
+		total -= len(value) - len(cut)
+		truncated = append(truncated, key)
+	}
+
+	// Truncate the message if the fields alone are not sufficient
+	if total > limit && len(msg) > len(truncationSuffix) {
+		keep := max(len(msg)-(total-limit)-len(truncationSuffix), 0)
+		msg = cutMessage(msg, keep) + truncationSuffix
+		truncated = append(truncated, "msg")
+	}
+
+	data.set("truncated_fields", truncated)
+
+	return msg
+}
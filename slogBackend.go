@@ -0,0 +1,129 @@
+package FlowWatch
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// Additional slog levels for the levels which are not modeled by slog.
+const (
+	slogLevelTrace = slog.LevelDebug - 4
+	slogLevelFatal = slog.LevelError + 4
+	slogLevelPanic = slog.LevelError + 8
+)
+
+// SlogBackend is the Backend implementation based on the standard log/slog package. It processes the entries like the
+// default hooks of the LogrusBackend (refer to DefaultHooks): the caller, the trace context, the replacement of large
+// binary fields and the size guard are applied to every entry, entries at warning level and higher are added to the
+// span as events and fatal and panic entries shut down the OpenTelemetry connection.
+type SlogBackend struct {
+	handler slog.Handler
+	level   *slog.LevelVar
+}
+
+// NewSlogBackend creates a backend writing to the slog handler. The level is controlled by the backend, so the
+// handler should accept all levels.
+func NewSlogBackend(handler slog.Handler) *SlogBackend {
+	return &SlogBackend{handler: handler, level: new(slog.LevelVar)}
+}
+
+// Log writes the entry if the level is enabled.
+func (b *SlogBackend) Log(level Level, ctx context.Context, fields Fields, msg string) {
+	if !b.IsLevelEnabled(level) {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	pc, _, _ := callerFrame()
+	data, msg := processEntry(ctx, level, fields, msg)
+	record := slog.NewRecord(time.Now(), slogLevel(level), msg, pc)
+
+	// Add the attributes in a stable order
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		record.AddAttrs(slog.Any(key, data[key]))
+	}
+
+	exportEntry(ctx, level, msg, data, record.Time)
+
+	if b.handler.Enabled(ctx, record.Level) {
+		_ = b.handler.Handle(ctx, record) // A failed write cannot be logged anywhere else
+	}
+
+	if level == Panic {
+		panic(msg)
+	}
+}
+
+// IsLevelEnabled reports whether entries at the level are written.
+func (b *SlogBackend) IsLevelEnabled(level Level) bool {
+	return slogLevel(level) >= b.level.Level()
+}
+
+// GetLevel returns the current log level.
+func (b *SlogBackend) GetLevel() Level {
+	switch current := b.level.Level(); {
+	case current <= slogLevelTrace:
+		return Trace
+	case current <= slog.LevelDebug:
+		return Debug
+	case current <= slog.LevelInfo:
+		return Info
+	case current <= slog.LevelWarn:
+		return Warn
+	case current <= slog.LevelError:
+		return Error
+	case current <= slogLevelFatal:
+		return Fatal
+	default:
+		return Panic
+	}
+}
+
+// SetLevel updates the log level.
+func (b *SlogBackend) SetLevel(level Level) {
+	b.level.Set(slogLevel(level))
+}
+
+// Child returns a backend sharing the handler with its own log level.
+func (b *SlogBackend) Child() Backend {
+	child := NewSlogBackend(b.handler)
+	child.level.Set(b.level.Level())
+
+	return child
+}
+
+// Flush is a no-op, since slog handlers write synchronously.
+func (b *SlogBackend) Flush() error {
+	return nil
+}
+
+// slogLevel translates the Level enumeration to the slog log level.
+func slogLevel(level Level) slog.Level {
+	switch level {
+	case Trace:
+		return slogLevelTrace
+	case Debug:
+		return slog.LevelDebug
+	case Info:
+		return slog.LevelInfo
+	case Warn:
+		return slog.LevelWarn
+	case Error:
+		return slog.LevelError
+	case Fatal:
+		return slogLevelFatal
+	case Panic:
+		return slogLevelPanic
+	default:
+		return slog.LevelDebug
+	}
+}
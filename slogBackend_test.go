@@ -0,0 +1,48 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogBackendProcessesEntries(t *testing.T) {
+	SetMaxEntrySize(4096)
+	t.Cleanup(func() { SetMaxEntrySize(DefaultMaxEntrySize) })
+
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slogLevelTrace})
+	lh := NewLogHelper(WithBackend(NewSlogBackend(handler)), WithLevel(Debug))
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}, TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+	fields := Fields{"payload": bytes.Repeat([]byte{1}, 2*DefaultBlobThreshold), "text": strings.Repeat("x", 8192)}
+	lh.WithFields(ctx, fields).Debug("processed")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid entry %q: %v", buf.String(), err)
+	}
+	if entry[TraceIDKey] != spanContext.TraceID().String() || entry[SpanIDKey] != spanContext.SpanID().String() {
+		t.Errorf("trace context missing: %v", entry)
+	}
+	if _, ok := entry["file"]; !ok {
+		t.Error("caller missing")
+	}
+	if blob, ok := entry["payload"].(map[string]interface{}); !ok || blob["sha256"] == nil {
+		t.Errorf("payload has not been replaced by its digest: %v", entry["payload"])
+	}
+	if text := entry["text"].(string); len(text) >= 8192 || !strings.HasSuffix(text, truncationSuffix) {
+		t.Errorf("oversize field has not been truncated (%d bytes)", len(text))
+	}
+	if _, ok := fields["file"]; ok || len(fields) != 2 {
+		t.Error("the fields of the caller have been modified")
+	}
+}
@@ -0,0 +1,118 @@
+package FlowWatch
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// slogHandler is a slog.Handler routing the records through the LogHelper (formatting, hooks and span events), so
+// that libraries which only accept slog still feed into FlowWatch.
+type slogHandler struct {
+	lh     *LogHelper
+	attrs  Fields
+	prefix string // Prefix of the attribute keys resulting from the groups
+}
+
+// NewSlogHandler returns a slog.Handler routing the records through the shared LogHelper instance.
+func NewSlogHandler() slog.Handler {
+	return GetLogHelper().SlogHandler()
+}
+
+// SlogHandler returns a slog.Handler routing the records through the LogHelper.
+func (lh *LogHelper) SlogHandler() slog.Handler {
+	return &slogHandler{lh: lh}
+}
+
+// Enabled reports whether records at the level are logged, taking the per-package levels into account.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.lh.isEnabled(levelFromSlog(level))
+}
+
+// Handle passes the record with its attributes through the LogHelper like the log functions (sampling, masking,
+// redaction and rate limiting). The level has already been checked by Enabled.
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	level := levelFromSlog(record.Level)
+	rate, sampled := h.lh.sample(level)
+	if !sampled {
+		return nil
+	}
+
+	fields := make(Fields, len(h.attrs)+record.NumAttrs()+2)
+	for key, value := range h.attrs {
+		fields[key] = value
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addSlogAttr(fields, h.prefix, attr)
+		return true
+	})
+
+	// Use the caller recorded by slog, since the call depth differs from the LogHelper functions
+	if record.PC != 0 && level != Info {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		fields["file"] = frame.File
+		fields["line"] = frame.Line
+	}
+
+	h.lh.write(ctx, level, fields, rate, record.Message)
+	return nil
+}
+
+// WithAttrs returns a handler which adds the attributes to all records.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(Fields, len(h.attrs)+len(attrs))
+	for key, value := range h.attrs {
+		fields[key] = value
+	}
+	for _, attr := range attrs {
+		addSlogAttr(fields, h.prefix, attr)
+	}
+
+	return &slogHandler{lh: h.lh, attrs: fields, prefix: h.prefix}
+}
+
+// WithGroup returns a handler which qualifies the keys of all following attributes with the group name.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{lh: h.lh, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// addSlogAttr adds the resolved attribute to the fields, flattening groups into dot-separated keys.
+func addSlogAttr(fields Fields, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = prefix + attr.Key + "."
+		}
+		for _, groupAttr := range value.Group() {
+			addSlogAttr(fields, groupPrefix, groupAttr)
+		}
+		return
+	}
+	if attr.Key == "" {
+		return // Empty attributes are ignored according to the slog.Handler contract
+	}
+
+	fields[strings.TrimSuffix(prefix+attr.Key, ".")] = value.Any()
+}
+
+// levelFromSlog translates the slog log level to the Level enumeration. Levels above the error level are mapped to
+// the error level, since libraries must not terminate the program through slog.
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return Trace
+	case level < slog.LevelInfo:
+		return Debug
+	case level < slog.LevelWarn:
+		return Info
+	case level < slog.LevelError:
+		return Warn
+	default:
+		return Error
+	}
+}
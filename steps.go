@@ -0,0 +1,139 @@
+package FlowWatch
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// StepSkippedError is returned by Steps.Run if the step has been skipped because a previous step failed.
+var StepSkippedError = errors.New("Step skipped after a previous step failed")
+
+// Statuses of the steps in the summary.
+const (
+	StepStatusOK      = "ok"
+	StepStatusFailed  = "failed"
+	StepStatusSkipped = "skipped"
+)
+
+// Steps tracks the named stages of a long-running task like a database migration or a data import. Each step is run
+// in a child span of the task span and the durations and statuses are logged as a summary table by End.
+type Steps struct {
+	ctx   context.Context
+	span  trace.Span
+	name  string
+	start time.Time
+
+	mu      sync.Mutex
+	results []stepResult
+	failed  bool
+}
+
+// stepResult is the outcome of a single step.
+type stepResult struct {
+	name     string
+	status   string
+	duration time.Duration
+	err      error
+}
+
+// StartSteps starts the span of the task with the given name. The steps are run with Steps.Run.
+func StartSteps(ctx context.Context, name string) *Steps {
+	ctx, span := tracer.Start(ctx, name)
+	return &Steps{ctx: ctx, span: span, name: name, start: time.Now()}
+}
+
+// Run runs the step in a child span and records its duration and status. Once a step has failed, the following
+// steps are skipped and StepSkippedError is returned.
+func (s *Steps) Run(name string, fn func(ctx context.Context) error) error {
+	s.mu.Lock()
+	failed := s.failed
+	s.mu.Unlock()
+	if failed {
+		s.record(stepResult{name: name, status: StepStatusSkipped})
+		return errors.Wrap(StepSkippedError, name)
+	}
+
+	ctx, span := tracer.Start(s.ctx, name)
+	defer span.End()
+
+	start := time.Now()
+	err := fn(ctx)
+	result := stepResult{name: name, status: StepStatusOK, duration: time.Since(start), err: err}
+
+	if err != nil {
+		result.status = StepStatusFailed
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.SetAttributes(attribute.String("step.status", result.status))
+	s.record(result)
+
+	return err
+}
+
+// End ends the span of the task and logs the summary table of the steps. The entry is logged at the error level if a
+// step has failed. The error of the failed step is returned.
+func (s *Steps) End() error {
+	s.mu.Lock()
+	results := append([]stepResult(nil), s.results...)
+	s.mu.Unlock()
+
+	var failure *stepResult
+	for i := range results {
+		if results[i].status == StepStatusFailed {
+			failure = &results[i]
+			break
+		}
+	}
+
+	elapsed := time.Since(s.start)
+	entry := GetLogHelper().WithFields(s.ctx, Fields{
+		"task":       s.name,
+		"steps":      len(results),
+		"elapsed_ms": elapsed.Milliseconds(),
+	})
+	msg := fmt.Sprintf("Finished %s in %s\n%s", s.name, elapsed.Round(time.Millisecond), stepsTable(results))
+
+	defer s.span.End()
+	if failure != nil {
+		s.span.SetStatus(codes.Error, fmt.Sprintf("Step %s failed", failure.name))
+		entry.WithError(failure.err).Error(msg)
+		return failure.err
+	}
+	entry.Info(msg)
+
+	return nil
+}
+
+// record appends the result of a step.
+func (s *Steps) record(result stepResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.results = append(s.results, result)
+	if result.status == StepStatusFailed {
+		s.failed = true
+	}
+}
+
+// stepsTable formats the results as an aligned table.
+func stepsTable(results []stepResult) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(w, "STEP\tSTATUS\tDURATION")
+	for _, result := range results {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", result.name, result.status, result.duration.Round(time.Millisecond))
+	}
+	_ = w.Flush() // Writing to a strings.Builder cannot fail
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,124 @@
+package FlowWatch
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Values of the log struct tag.
+const (
+	logTagRedact = "redact" // The value is replaced with the placeholder
+	logTagOmit   = "omit"   // The field is left out
+)
+
+// maskedTypes caches whether a type contains fields with a log tag (reflect.Type -> bool).
+var maskedTypes sync.Map
+
+// maskStructFields returns the fields with the structs honoring the log struct tags, e.g.:
+//
+//	type User struct {
+//		Name     string `json:"name"`
+//		Password string `json:"password" log:"redact"`
+//		Session  string `log:"omit"`
+//	}
+//
+// Structs (and pointers, slices and maps of them) containing tagged fields are converted into maps with the JSON field
+// names. The fields are copied if a value is converted, since they may be shared by an Entry.
+func (lh *LogHelper) maskStructFields(fields Fields) Fields {
+	placeholder := DefaultRedactionPlaceholder
+	if r := lh.root.redactor.Load(); r != nil {
+		placeholder = r.placeholder
+	}
+
+	copied := false
+	for key, value := range fields {
+		if value == nil || !hasLogTags(reflect.TypeOf(value)) {
+			continue
+		}
+		if !copied {
+			fields = copyFields(fields)
+			copied = true
+		}
+		fields[key] = maskValue(reflect.ValueOf(value), placeholder)
+	}
+	return fields
+}
+
+// hasLogTags reports whether the type contains a struct field with a log tag (also in nested types).
+func hasLogTags(t reflect.Type) bool {
+	if cached, ok := maskedTypes.Load(t); ok {
+		return cached.(bool)
+	}
+
+	maskedTypes.Store(t, false) // Break the recursion of self-referencing types
+	tagged := false
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		tagged = hasLogTags(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField() && !tagged; i++ {
+			field := t.Field(i)
+			tagged = field.IsExported() && (field.Tag.Get("log") != "" || hasLogTags(field.Type))
+		}
+	}
+	maskedTypes.Store(t, tagged)
+
+	return tagged
+}
+
+// maskValue converts the value into a representation without the redacted and omitted fields.
+func maskValue(value reflect.Value, placeholder string) interface{} {
+	if !value.IsValid() || !hasLogTags(value.Type()) {
+		if !value.IsValid() || !value.CanInterface() {
+			return nil
+		}
+		return value.Interface()
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			return nil
+		}
+		return maskValue(value.Elem(), placeholder)
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+		masked := make([]interface{}, value.Len())
+		for i := range masked {
+			masked[i] = maskValue(value.Index(i), placeholder)
+		}
+		return masked
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+		masked := make(map[string]interface{}, value.Len())
+		for iter := value.MapRange(); iter.Next(); {
+			masked[fmt.Sprint(iter.Key().Interface())] = maskValue(iter.Value(), placeholder)
+		}
+		return masked
+	}
+
+	// Structs are converted into maps with the JSON field names
+	masked := make(map[string]interface{}, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		switch field.Tag.Get("log") {
+		case logTagOmit:
+		case logTagRedact:
+			redactions.Add(1)
+			masked[name] = placeholder
+		default:
+			masked[name] = maskValue(value.Field(i), placeholder)
+		}
+	}
+	return masked
+}
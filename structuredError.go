@@ -0,0 +1,101 @@
+package FlowWatch
+
+import (
+	stderrors "errors"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"runtime"
+)
+
+// ErrorObject is the structured representation of an error attached with WithError, which keeps the wrapped causes
+// and the stack trace queryable instead of flattening them to a string.
+type ErrorObject struct {
+	Type    string       `json:"type"`             // Type of the root cause
+	Message string       `json:"message"`          // Message of the error including the messages of the wrappers
+	Causes  []ErrorCause `json:"causes,omitempty"` // Wrapped errors from the outermost to the root cause
+	Stack   []StackFrame `json:"stack,omitempty"`  // Stack trace of the deepest error providing one
+}
+
+// ErrorCause is a wrapped error of an ErrorObject.
+type ErrorCause struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// StackFrame is a frame of the stack trace of an ErrorObject.
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// NewErrorObject returns the structured representation of the error.
+func NewErrorObject(err error) ErrorObject {
+	object := ErrorObject{Message: err.Error()}
+
+	// Describe the original error of a redacted error with the masked messages (refer to SetRedaction)
+	mask := func(message string) string { return message }
+	if redacted, ok := err.(*redactedError); ok {
+		mask = redacted.maskMessage
+		err = redacted.err
+	}
+
+	// Collect the causes, skipping the wrappers of pkg/errors that only add a stack (same message)
+	var stack errors.StackTrace
+	message := err.Error()
+	root := err
+	for cause := stderrors.Unwrap(err); cause != nil; cause = stderrors.Unwrap(cause) {
+		root = cause
+		if tracer, ok := cause.(stackTracer); ok {
+			stack = tracer.StackTrace()
+		}
+		if cause.Error() == message {
+			continue
+		}
+		message = cause.Error()
+		object.Causes = append(object.Causes, ErrorCause{Type: fmt.Sprintf("%T", cause), Message: mask(message)})
+	}
+	object.Type = fmt.Sprintf("%T", root)
+	if tracer, ok := err.(stackTracer); ok && stack == nil {
+		stack = tracer.StackTrace()
+	}
+
+	for _, frame := range stack {
+		pc := uintptr(frame) - 1 // The frames are return addresses
+		function := runtime.FuncForPC(pc)
+		if function == nil {
+			continue
+		}
+		file, line := function.FileLine(pc)
+		object.Stack = append(object.Stack, StackFrame{Function: function.Name(), File: file, Line: line})
+	}
+
+	return object
+}
+
+// JSONFormatter is the default JSON formatter of FlowWatch. It writes an error attached with WithError as structured
+// ErrorObject (containing the stack trace instead of the separate stack field).
+type JSONFormatter struct {
+	logrus.JSONFormatter
+}
+
+// Format formats the entry as JSON line.
+func (f *JSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	err, ok := entry.Data[ErrorKey].(error)
+	if !ok {
+		return f.JSONFormatter.Format(entry)
+	}
+
+	data := make(logrus.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		if key != StackKey {
+			data[key] = value
+		}
+	}
+	data[ErrorKey] = NewErrorObject(err)
+
+	structured := *entry
+	structured.Data = data
+	return f.JSONFormatter.Format(&structured)
+}
@@ -0,0 +1,94 @@
+package FlowWatch
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"testing"
+)
+
+//go:noinline
+func newStackError() error {
+	return errors.New("connection refused")
+}
+
+func TestNewErrorObject(t *testing.T) {
+	root := newStackError()
+	err := fmt.Errorf("failed to query: %w", errors.Wrap(root, "failed to connect"))
+
+	object := NewErrorObject(err)
+	if object.Type != fmt.Sprintf("%T", root) {
+		t.Errorf("type = %q, want the type of the root cause", object.Type)
+	}
+	if object.Message != err.Error() {
+		t.Errorf("message = %q, want %q", object.Message, err.Error())
+	}
+
+	// The wrapper of errors.Wrap adding only the stack is skipped
+	want := []string{"failed to connect: connection refused", "connection refused"}
+	if len(object.Causes) != len(want) {
+		t.Fatalf("causes = %+v, want %v", object.Causes, want)
+	}
+	for i, cause := range object.Causes {
+		if cause.Message != want[i] {
+			t.Errorf("cause %d = %q, want %q", i, cause.Message, want[i])
+		}
+	}
+
+	// The stack of the deepest error is kept
+	if len(object.Stack) == 0 || !strings.HasSuffix(object.Stack[0].Function, ".newStackError") {
+		t.Errorf("stack does not start at the creation of the root cause: %+v", object.Stack)
+	}
+	if object.Stack[0].File == "" || object.Stack[0].Line == 0 {
+		t.Errorf("frame without location: %+v", object.Stack[0])
+	}
+}
+
+func TestNewErrorObjectWithoutStack(t *testing.T) {
+	object := NewErrorObject(stderrors.New("plain"))
+	if object.Type != "*errors.errorString" || object.Message != "plain" {
+		t.Errorf("unexpected object %+v", object)
+	}
+	if object.Causes != nil || object.Stack != nil {
+		t.Errorf("plain error has causes or a stack: %+v", object)
+	}
+}
+
+func TestJSONFormatterErrorObject(t *testing.T) {
+	err := errors.Wrap(newStackError(), "failed to connect")
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+		ErrorKey: err,
+		StackKey: errorStack(err),
+		"user":   "alice",
+	})
+	entry.Message = "request failed"
+
+	line, formatErr := (&JSONFormatter{}).Format(entry)
+	if formatErr != nil {
+		t.Fatal(formatErr)
+	}
+
+	var decoded struct {
+		Error ErrorObject `json:"error"`
+		Stack *string     `json:"stack"`
+		User  string      `json:"user"`
+	}
+	if err := json.Unmarshal(line, &decoded); err != nil {
+		t.Fatalf("invalid line %q: %v", line, err)
+	}
+	if decoded.Error.Message != err.Error() || len(decoded.Error.Stack) == 0 {
+		t.Errorf("error object not written: %s", line)
+	}
+	if decoded.Stack != nil {
+		t.Error("the separate stack field has not been dropped")
+	}
+	if decoded.User != "alice" {
+		t.Error("the other fields have not been kept")
+	}
+	if _, ok := entry.Data[StackKey]; !ok {
+		t.Error("the fields of the entry have been modified")
+	}
+}
@@ -0,0 +1,176 @@
+package FlowWatch
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSyslogFacility is the default syslog facility (user-level messages).
+const DefaultSyslogFacility = 1
+
+// syslogDialTimeout bounds the connection attempts to the syslog server.
+const syslogDialTimeout = 5 * time.Second
+
+// localSyslogSockets are the paths of the local syslog socket on the common platforms.
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogConnectionError is returned if no connection to the syslog server could be established.
+var SyslogConnectionError = errors.New("Failed to connect to syslog")
+
+// SyslogConfig configures the LogrusSyslogHook.
+type SyslogConfig struct {
+	Network     string      // udp, tcp or tcp+tls for remote servers, empty for the local socket
+	Address     string      // Address of the remote server, e.g. syslog:6514
+	TLSConfig   *tls.Config // TLS configuration for tcp+tls (default: the system roots)
+	Facility    int         // Syslog facility (default: DefaultSyslogFacility, 16 to 23 for local0 to local7)
+	AppName     string      // Name of the application (default: the name of the executable)
+	MaxBuffered int         // Messages buffered while the server is unreachable (default: DefaultBatchBufferedEvents)
+}
+
+// LogrusSyslogHook is a hook for logrus that writes the entries as RFC 5424 messages to the local syslog socket or
+// to a remote server via UDP, TCP or TCP with TLS. The severity is mapped from the level and the fields are appended
+// to the message as key=value pairs. The messages are written from a background goroutine, so that an unreachable
+// server does not block the logging goroutines.
+type LogrusSyslogHook struct {
+	config   SyslogConfig
+	hostname string
+	batcher  *batcher
+	conn     sinkConn // Connection to the syslog server, only used by the batcher
+}
+
+// NewSyslogHook creates a hook writing the entries to the syslog server configured by the config.
+func NewSyslogHook(config SyslogConfig) *LogrusSyslogHook {
+	if config.Facility <= 0 {
+		config.Facility = DefaultSyslogFacility
+	}
+	if config.AppName == "" {
+		config.AppName = filepath.Base(os.Args[0])
+	}
+	hostname, _ := os.Hostname()
+
+	hook := &LogrusSyslogHook{config: config, hostname: hostname}
+	hook.conn.dial = hook.dial
+	hook.batcher = newBatcher(batchConfig{
+		maxEvents: 1, // Every message is written on its own, so that a failed write does not repeat written messages
+		interval:  sinkSendInterval,
+		retries:   -1, // The reconnects are backed off by the connection
+
+		maxBufferedEvents: config.MaxBuffered,
+	}, hook.send)
+
+	return hook
+}
+
+// Levels returns all log levels for which the LogrusSyslogHook should be activated (all levels).
+func (hook *LogrusSyslogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire is called when the LogrusSyslogHook is activated (when a log entry is made). The message is formatted and
+// queued for the background goroutine.
+func (hook *LogrusSyslogHook) Fire(entry *logrus.Entry) error {
+	message := hook.message(entry)
+
+	// Stream transports need framing (octet counting according to RFC 6587)
+	if hook.config.Network == "tcp" || hook.config.Network == "tcp+tls" {
+		message = append([]byte(strconv.Itoa(len(message))+" "), message...)
+	}
+
+	hook.batcher.add(batchEvent{time: entry.Time, level: levelFromLogrus(entry.Level), line: string(message)})
+	return nil
+}
+
+// Flush writes the queued messages.
+func (hook *LogrusSyslogHook) Flush(ctx context.Context) error {
+	return hook.batcher.Flush(ctx)
+}
+
+// Close writes the remaining messages, stops the background goroutine and closes the connection to the syslog server.
+func (hook *LogrusSyslogHook) Close(ctx context.Context) error {
+	err := hook.batcher.Close(ctx)
+	if closeErr := hook.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// send writes the message of the batch (refer to NewSyslogHook).
+func (hook *LogrusSyslogHook) send(_ context.Context, events []batchEvent) error {
+	for _, event := range events {
+		if err := hook.conn.write([]byte(event.line)); err != nil {
+			return errors.Wrap(err, "Failed to write the syslog message")
+		}
+	}
+	return nil
+}
+
+// dial connects to the configured remote server or to the first available local socket.
+func (hook *LogrusSyslogHook) dial() (net.Conn, error) {
+	switch hook.config.Network {
+	case "tcp+tls":
+		dialer := &net.Dialer{Timeout: syslogDialTimeout}
+		conn, err := tls.DialWithDialer(dialer, "tcp", hook.config.Address, hook.config.TLSConfig)
+		return conn, errors.Wrapf(err, "Failed to connect to %s", hook.config.Address)
+	case "":
+		for _, path := range localSyslogSockets {
+			for _, network := range []string{"unixgram", "unix"} {
+				if conn, err := net.DialTimeout(network, path, syslogDialTimeout); err == nil {
+					return conn, nil
+				}
+			}
+		}
+		return nil, errors.Wrap(SyslogConnectionError, "No local syslog socket found")
+	default:
+		conn, err := net.DialTimeout(hook.config.Network, hook.config.Address, syslogDialTimeout)
+		return conn, errors.Wrapf(err, "Failed to connect to %s", hook.config.Address)
+	}
+}
+
+// message formats the entry as RFC 5424 message: <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+func (hook *LogrusSyslogHook) message(entry *logrus.Entry) []byte {
+	priority := hook.config.Facility*8 + syslogSeverity(levelFromLogrus(entry.Level))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %d - - %s", priority, entry.Time.Format(time.RFC3339Nano),
+		syslogHeaderValue(hook.hostname), syslogHeaderValue(hook.config.AppName), os.Getpid(), entry.Message)
+
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var fields bytes.Buffer
+	for _, key := range keys {
+		value := entry.Data[key]
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		writeLogfmtPair(&fields, key, fmt.Sprint(value))
+	}
+	if fields.Len() > 0 {
+		buf.WriteByte(' ')
+		buf.Write(fields.Bytes())
+	}
+
+	return buf.Bytes()
+}
+
+// syslogHeaderValue returns the value for a header field, which must not be empty or contain spaces.
+func syslogHeaderValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(value, " ", "_")
+}
@@ -0,0 +1,54 @@
+package FlowWatch
+
+import (
+	"context"
+	stderrors "errors"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"time"
+)
+
+// OperationTimeoutError is returned by WithTimeout if the operation exceeded its timeout.
+var OperationTimeoutError = errors.New("Operation timed out")
+
+var tracer = otel.Tracer("FlowWatch")
+
+// WithTimeout runs fn in a child span named after the operation and cancels its context after the timeout. If the
+// timeout is exceeded, a structured timeout error including the actual runtime is logged, recorded on the span and
+// returned (wrapping OperationTimeoutError, joined with the error of fn if it returned one). Other errors of fn are
+// recorded on the span and returned unchanged.
+func WithTimeout(ctx context.Context, timeout time.Duration, name string, fn func(ctx context.Context) error) error {
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(timeoutCtx)
+	elapsed := time.Since(start)
+
+	if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		timeoutErr := errors.Wrapf(OperationTimeoutError, "%s exceeded its timeout of %s (ran for %s)", name, timeout,
+			elapsed)
+		if err != nil {
+			timeoutErr = stderrors.Join(timeoutErr, err) // Keep the error of fn, e.g. to inspect it with errors.Is
+		}
+		err = timeoutErr
+		GetLogHelper().WithError(ctx, err).WithFields(Fields{
+			"operation":  name,
+			"timeout":    timeout.String(),
+			"elapsed":    elapsed.String(),
+			"elapsed_ms": elapsed.Milliseconds(),
+		}).Error("Operation timed out")
+		return err
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
@@ -0,0 +1,119 @@
+package FlowWatch
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sync/atomic"
+	"time"
+)
+
+// zapLevelTrace is the additional zap level for the trace level, which is not modeled by zap.
+const zapLevelTrace = zapcore.DebugLevel - 1
+
+// ZapBackend is the Backend implementation based on zap for high-throughput services. It processes the entries like the
+// default hooks of the LogrusBackend (refer to DefaultHooks): the caller, the trace context, the replacement of large
+// binary fields and the size guard are applied to every entry, entries at warning level and higher are added to the
+// span as events and fatal and panic entries shut down the OpenTelemetry connection.
+type ZapBackend struct {
+	logger *zap.Logger
+	level  atomic.Uint32 // Level enumeration, since zap orders its panic level below the fatal level
+}
+
+// NewZapBackend creates a backend writing to the zap logger. The level is controlled by the backend, so the core of
+// the logger should accept all levels (including the trace level, which is one below zap's debug level).
+func NewZapBackend(logger *zap.Logger) *ZapBackend {
+	// The LogHelper terminates the program after fatal entries (refer to WithExitFunc)
+	logger = logger.WithOptions(zap.WithFatalHook(noopFatalHook{}))
+
+	backend := &ZapBackend{logger: logger}
+	backend.level.Store(uint32(Info))
+
+	return backend
+}
+
+// noopFatalHook keeps zap from terminating the program after fatal entries (zap replaces zapcore.WriteThenNoop).
+type noopFatalHook struct{}
+
+// OnWrite does nothing after the fatal entry has been written.
+func (noopFatalHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {}
+
+// Log writes the entry if the level is enabled.
+func (b *ZapBackend) Log(level Level, ctx context.Context, fields Fields, msg string) {
+	if !b.IsLevelEnabled(level) {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	data, msg := processEntry(ctx, level, fields, msg)
+	exportEntry(ctx, level, msg, data, time.Now())
+
+	checked := b.logger.Check(zapLevel(level), msg)
+	if checked == nil {
+		// Keep the panic semantics even if the core does not write the entry
+		if level == Panic {
+			panic(msg)
+		}
+		return
+	}
+
+	zapFields := make([]zap.Field, 0, len(data))
+	for key, value := range data {
+		zapFields = append(zapFields, zap.Any(key, value))
+	}
+
+	checked.Write(zapFields...) // Panics after panic entries
+}
+
+// IsLevelEnabled reports whether entries at the level are written.
+func (b *ZapBackend) IsLevelEnabled(level Level) bool {
+	return level.AtLeast(b.GetLevel())
+}
+
+// GetLevel returns the current log level.
+func (b *ZapBackend) GetLevel() Level {
+	return Level(b.level.Load())
+}
+
+// SetLevel updates the log level.
+func (b *ZapBackend) SetLevel(level Level) {
+	b.level.Store(uint32(level))
+}
+
+// Child returns a backend sharing the zap logger with its own log level.
+func (b *ZapBackend) Child() Backend {
+	child := NewZapBackend(b.logger)
+	child.level.Store(b.level.Load())
+
+	return child
+}
+
+// Flush flushes the buffered entries of the zap logger.
+func (b *ZapBackend) Flush() error {
+	return ignoreUnsupportedSync(b.logger.Sync()) // zap syncs stderr even if it is a pipe or terminal
+}
+
+// zapLevel translates the Level enumeration to the zap log level of the written entry (not used for the level check,
+// since zap's panic level is below its fatal level).
+func zapLevel(level Level) zapcore.Level {
+	switch level {
+	case Trace:
+		return zapLevelTrace
+	case Debug:
+		return zapcore.DebugLevel
+	case Info:
+		return zapcore.InfoLevel
+	case Warn:
+		return zapcore.WarnLevel
+	case Error:
+		return zapcore.ErrorLevel
+	case Fatal:
+		return zapcore.FatalLevel
+	case Panic:
+		return zapcore.PanicLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
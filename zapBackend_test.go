@@ -0,0 +1,62 @@
+package FlowWatch
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"strings"
+	"testing"
+)
+
+func TestZapBackendProcessesEntries(t *testing.T) {
+	SetMaxEntrySize(4096)
+	t.Cleanup(func() { SetMaxEntrySize(DefaultMaxEntrySize) })
+
+	core, logs := observer.New(zapLevelTrace)
+	lh := NewLogHelper(WithBackend(NewZapBackend(zap.New(core))), WithLevel(Debug))
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}, TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+	fields := Fields{"payload": make([]byte, 2*DefaultBlobThreshold), "text": strings.Repeat("x", 8192)}
+	lh.WithFields(ctx, fields).Debug("processed")
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("%d entries written, want 1", len(entries))
+	}
+	entry := entries[0].ContextMap()
+	if entry[TraceIDKey] != spanContext.TraceID().String() || entry[SpanIDKey] != spanContext.SpanID().String() {
+		t.Errorf("trace context missing: %v", entry)
+	}
+	if _, ok := entry["file"]; !ok {
+		t.Error("caller missing")
+	}
+	if blob, ok := entry["payload"].(map[string]interface{}); !ok || blob["sha256"] == nil {
+		t.Errorf("payload has not been replaced by its digest: %v", entry["payload"])
+	}
+	if text := entry["text"].(string); len(text) >= 8192 || !strings.HasSuffix(text, truncationSuffix) {
+		t.Errorf("oversize field has not been truncated (%d bytes)", len(text))
+	}
+	if _, ok := fields["file"]; ok || len(fields) != 2 {
+		t.Error("the fields of the caller have been modified")
+	}
+}
+
+func TestZapBackendPanics(t *testing.T) {
+	core, logs := observer.New(zapLevelTrace)
+	backend := NewZapBackend(zap.New(core))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("panic entry did not panic")
+		}
+		if logs.Len() != 1 {
+			t.Errorf("%d entries written, want 1", logs.Len())
+		}
+	}()
+	backend.Log(Panic, context.WithValue(context.Background(), recoveredPanicKey{}, true), nil, "panic")
+}